@@ -3,13 +3,22 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3" // DB 드라이버
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/metrics"
 	"restaurant_db/internal/model"
+	"restaurant_db/internal/reliability"
 	"restaurant_db/internal/repository"
 	"restaurant_db/internal/worker"
 	"restaurant_db/service"
@@ -20,157 +29,491 @@ const (
 	TestWriteCount  = 1000 // 쓰기 요청 횟수
 	TestReadCount   = 100  // 읽기 성능 측정 반복 횟수
 	WorkerBatchSize = 100
+
+	// L1 메모리 캐시 설정
+	MemoryCacheCapacity = 1000
+	MemoryCacheTTL      = 30 * time.Second
+
+	// 캐시 정책이 모아 둔 조회 기록을 쓰고 점수 감쇠/퇴출/승격을 실행하는 주기
+	CachePolicyInterval = time.Second
+
+	// 반영된 지 -buffer-retention이 지난 버퍼 로그를 정리하는 주기
+	BufferCompactInterval = time.Hour
+
+	// 종료 시 CheckpointWorker가 남은 버퍼 로그를 비우는 데 쓸 수 있는 최대 시간
+	ShutdownTimeout = 5 * time.Second
 )
 
-// setupDB: 테스트용 인메모리 DB를 설정하고 스키마를 초기화합니다.
-func setupDB() *sql.DB {
-	// 1. SQLite 인메모리 DB 연결
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+// dsn: 사용할 DB (기본값은 인메모리, 파일 경로를 주면 디스크 DB를 열고 마이그레이션을 적용)
+var dsn = flag.String("db", db.MemoryDSN, "SQLite DSN or database file path")
+
+// scorerStrategy: 신뢰도 계산 전략 (bias_ratio, consensus, bayesian)
+var scorerStrategy = flag.String("scorer", reliability.StrategyBiasRatio, "reliability scoring strategy")
+
+// bufferRetention, bufferArchive: 반영된 버퍼 로그를 남겨 두는 기간과 지우기 전에 압축 보관할 디렉터리 (비어 있으면 보관 안 함)
+var bufferRetention = flag.Duration("buffer-retention", worker.DefaultBufferRetention, "how long committed buffer logs are kept")
+var bufferArchive = flag.String("buffer-archive", "", "directory for gzip NDJSON archives of compacted buffer logs")
+
+// freshnessSLO: 버퍼 로그가 반영되기까지 목표 시간. CheckpointWorker가 이에 맞춰 배치 크기와 대기 시간을 조정 (0이면 WorkerBatchSize 고정)
+var freshnessSLO = flag.Duration("freshness-slo", worker.DefaultFreshnessSLO, "target time from AddLog to apply; 0 uses a fixed batch size")
+
+// logFormat, logLevel: 로그 출력 형식(text, json)과 최소 레벨(debug, info, warn, error)
+var logFormat = flag.String("log-format", logging.FormatText, "log output format: text or json")
+var logLevel = flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+
+// metricsAddr: Prometheus 텍스트 형식 /metrics를 제공할 주소 (예: 127.0.0.1:9464, 비어 있으면 계측하지 않음)
+// 주소가 있으면 시뮬레이션이 끝난 뒤에도 SIGINT/SIGTERM까지 계속 제공
+var metricsAddr = flag.String("metrics-addr", "", "address for the Prometheus /metrics endpoint (e.g. 127.0.0.1:9464); empty disables metrics")
+
+// setupLogger: 플래그에 맞는 로거를 만들어 slog 기본 로거로도 설정합니다.
+func setupLogger() *slog.Logger {
+	level, err := logging.ParseLevel(*logLevel)
 	if err != nil {
-		log.Fatalf("could not open database connection: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-
-	// 2. 스키마 로드 및 실행
-	// 경로는 현재 main.go를 기준으로 설정해야 합니다. (프로젝트 루트에서 실행 가정)
-	schemaSQL, err := os.ReadFile("internal/db/schema.sql")
+	logger, err := logging.New(logging.Config{Format: *logFormat, Level: level, Output: os.Stdout})
 	if err != nil {
-		// Go 파일 위치에 따라 경로를 조정해야 할 수 있습니다.
-		log.Fatalf("could not read schema file: %v. Check path.", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+	return logger
+}
+
+// fatal: 오류를 기록하고 종료합니다.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+// fail: err가 없으면 false를 반환합니다. 종료 신호로 ctx가 취소되어 생긴 오류면 시뮬레이션을 멈추도록 true만 반환하고,
+// 그 밖의 오류는 fatal로 종료합니다.
+func fail(ctx context.Context, logger *slog.Logger, msg string, err error) bool {
+	if err == nil {
+		return false
+	}
+	if ctx.Err() != nil {
+		return true
 	}
-	_, err = db.Exec(string(schemaSQL))
+	fatal(logger, msg, err)
+	return true
+}
+
+// setupDB: DB를 열고 내장 스키마와 마이그레이션을 적용합니다.
+func setupDB(logger *slog.Logger) *sql.DB {
+	conn, err := db.InitDB(*dsn)
 	if err != nil {
-		log.Fatalf("could not execute schema: %v", err)
+		fatal(logger, "could not initialize database", err)
 	}
 
-	return db
+	// 공유 캐시 인메모리 DB는 연결끼리 테이블 잠금을 걸어 동시 쓰기가 SQLITE_LOCKED로 실패하므로,
+	// 백그라운드 Worker와 시뮬레이션이 연결 하나를 번갈아 쓰도록 함 (파일 DB는 WAL과 busy_timeout으로 처리)
+	if *dsn == "" || strings.Contains(*dsn, "memory") {
+		conn.SetMaxOpenConns(1)
+	}
+
+	return conn
+}
+
+// system: 시뮬레이션에 필요한 Repository, Service, Worker 묶음
+type system struct {
+	logger  *slog.Logger
+	metrics *metrics.Metrics // -metrics-addr가 없으면 nil
+
+	bufferRepo     repository.BufferRepository
+	userRepo       repository.UserRepository
+	restaurantRepo repository.RestaurantRepository
+	reviewRepo     repository.ReviewRepository
+	analysisRepo   repository.AnalysisLogRepository
+	// cacheStore: Cache_Metadata 테이블 (L2), cacheRepo: 그 앞의 L1 메모리 캐시
+	cacheStore repository.CacheRepository
+	cacheRepo  repository.CacheRepository
+
+	restaurantService *service.RestaurantService
+	reviewService     *service.ReviewService
+
+	memoryCache      *cache.MemoryCache
+	aggregator       *cache.RatingAggregator
+	cachePolicy      *cache.Policy
+	analysisWorker   *worker.AnalysisWorker
+	checkpointWorker *worker.CheckpointWorker
+	compactor        *worker.Compactor
 }
 
 // initSystem: 모든 Repository와 Service, Worker를 초기화하고 연결합니다.
-func initSystem(db *sql.DB) (repository.BufferRepository, repository.UserRepository, service.RestaurantService) {
+func initSystem(db *sql.DB, logger *slog.Logger) *system {
+	s := &system{logger: logger}
+
+	// 버퍼 로그 핸들러 (AddLog 검증과 CheckpointWorker 반영에 같은 Registry를 사용)
+	registry := buffer.DefaultRegistry()
+
 	// Repository 초기화
-	bufferRepo := repository.NewBufferRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	cacheRepo := repository.NewCacheRepository(db)
-	restaurantRepo := repository.NewRestaurantRepository(db)
+	repoLogger := repository.WithRepositoryLogger(logger)
+	s.userRepo = repository.NewUserRepository(db, repoLogger)
+	s.cacheStore = repository.NewCacheRepository(db, repoLogger)
+	s.restaurantRepo = repository.NewRestaurantRepository(db, repoLogger)
+	s.reviewRepo = repository.NewReviewRepository(db, repoLogger)
+	s.analysisRepo = repository.NewAnalysisLogRepository(db, repoLogger)
+
+	// 지표를 내보내면 Repository를 계측 데코레이터로 감쌈 (L1 메모리 캐시 뒤의 Cache_Metadata 접근만 기록)
+	if *metricsAddr != "" {
+		s.metrics = metrics.New()
+		s.userRepo = metrics.NewUserRepository(s.userRepo, s.metrics)
+		s.cacheStore = metrics.NewCacheRepository(s.cacheStore, s.metrics)
+		s.restaurantRepo = metrics.NewRestaurantRepository(s.restaurantRepo, s.metrics)
+		s.reviewRepo = metrics.NewReviewRepository(s.reviewRepo, s.metrics)
+	}
+
+	// L1 메모리 캐시를 Cache_Metadata 앞에 두고, 이후 모든 캐시 접근은 L1을 거치게 함
+	// (Worker가 재계산하면 Upsert를 통해 L1 항목이 무효화됨)
+	s.memoryCache = cache.NewMemoryCache(s.cacheStore, MemoryCacheCapacity, MemoryCacheTTL)
+	s.cacheRepo = s.memoryCache
+
+	// 가중 평점 집계기 (캐시 미스 재구성과 Worker의 선제적 캐시 갱신에 함께 사용)
+	s.aggregator = cache.NewRatingAggregator(s.restaurantRepo, s.reviewRepo, s.cacheRepo)
+
+	// cache_score 기반 캐시 입장/퇴출 정책
+	// Worker가 선제 갱신으로 새로 만든 행도 초기 점수를 받아, 조회되기 전의 첫 Maintain에서 바로 내려가지 않음
+	policyConfig := cache.DefaultPolicyConfig()
+	s.aggregator.InitialScore = policyConfig.InitialScore
+	s.cachePolicy = cache.NewPolicy(s.cacheRepo, s.restaurantRepo, s.aggregator, policyConfig, logger)
+
+	// Worker 초기화 (버퍼 -> 릴레이션 반영 후 Cache_Metadata 선제 갱신)
+	// 로그가 WorkerBatchSize만큼 쌓이거나 첫 로그 후 100ms가 지나면 반영
+	// -freshness-slo가 있으면 두 값을 백로그와 반영 시간에 맞춰 조정
+	s.checkpointWorker = worker.NewCheckpointWorker(db, s.aggregator, WorkerBatchSize, 100*time.Millisecond, logger)
+	s.checkpointWorker.Registry = registry
+	if *freshnessSLO > 0 {
+		s.checkpointWorker.Adaptive = worker.NewAdaptiveController(worker.DefaultAdaptiveConfig(*freshnessSLO))
+	}
+
+	// 버퍼 Repository: 잘못된 payload는 쓰기 전에 거절하고, 새 로그는 CheckpointWorker에 바로 알림
+	s.bufferRepo = repository.NewBufferRepository(db,
+		repository.WithValidator(registry),
+		repository.WithNotifier(s.checkpointWorker),
+		repository.WithLogger(logger),
+	)
+	if s.metrics != nil {
+		s.bufferRepo = metrics.NewBufferRepository(s.bufferRepo, s.metrics)
+		s.checkpointWorker.BufferRepo = s.bufferRepo
+		s.checkpointWorker.Metrics = s.metrics
+		// Dead Letter 재투입도 같은 검증을 거치고 CheckpointWorker를 깨움
+		deadLetterRepo := repository.NewDeadLetterRepository(db,
+			repository.WithValidator(registry),
+			repository.WithNotifier(s.checkpointWorker),
+			repository.WithLogger(logger),
+		)
+		s.metrics.RegisterBufferGauges(s.bufferRepo, deadLetterRepo)
+	}
+
+	// 유저 신뢰도는 버퍼를 거쳐 갱신되므로, 리뷰 가중치 스냅샷과 신뢰도 재계산은 반영 대기 중인 User 로그까지 겹쳐 읽음
+	// (Worker가 아직 반영하지 않은 앞선 배치의 결과를 덮어쓰지 않도록)
+	userView := buffer.NewUserOverlay(s.userRepo, s.bufferRepo, registry)
 
 	// Service 초기화 (캐싱/릴레이션 접근 로직 포함)
-	restaurantService := service.NewRestaurantService(cacheRepo, restaurantRepo)
+	s.restaurantService = service.NewRestaurantService(s.cacheRepo, s.restaurantRepo, s.aggregator, s.cachePolicy, logger)
+	s.reviewService = service.NewReviewService(userView, s.bufferRepo)
+	s.restaurantService.BufferRepo = s.bufferRepo
+	s.restaurantService.Metrics = s.metrics
 
-	// Worker 초기화 (버퍼 -> UserRepo 접근 로직 포함)
-	_ = worker.NewCheckpointWorker(bufferRepo, userRepo, WorkerBatchSize, 100*time.Millisecond) // Worker는 시뮬레이션용이므로 Run은 하지 않습니다.
+	// 신뢰도 계산 전략 선택 (같은 데이터로 여러 모델을 비교할 수 있도록 설정으로 교체)
+	scorer, err := reliability.New(reliability.Config{Strategy: *scorerStrategy}, s.reviewRepo)
+	if err != nil {
+		fatal(logger, "invalid reliability scorer", err)
+	}
+	s.analysisWorker = worker.NewAnalysisWorker(s.analysisRepo, userView, s.bufferRepo, scorer, WorkerBatchSize, 100*time.Millisecond, logger)
 
-	return bufferRepo, userRepo, *restaurantService
+	// 반영된 지 오래된 버퍼 로그 정리
+	s.compactor = worker.NewCompactor(s.bufferRepo, *bufferRetention, *bufferArchive, BufferCompactInterval, logger)
+
+	return s
 }
 
 func main() {
+	flag.Parse()
+	logger := setupLogger()
+
 	// 1. 시스템 초기화 및 DB 설정
-	db := setupDB() // main에서는 *testing.T 대신 nil을 전달하거나 구조를 조정
-	defer db.Close()
+	conn := setupDB(logger)
+	defer conn.Close()
 
-	bufferRepo, userRepo, restaurantService := initSystem(db)
+	sys := initSystem(conn, logger)
 	ctx := context.Background()
 
+	// SIGINT/SIGTERM을 받으면 시뮬레이션을 멈추고 CheckpointWorker를 정상 종료
+	sigCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// CheckpointWorker는 백그라운드에서 알림(배치 크기/최대 지연)에 따라 버퍼를 반영하고,
+	// AnalysisWorker, 캐시 정책, Compactor는 주기마다 실행
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	go sys.checkpointWorker.Run(runCtx)
+	go sys.analysisWorker.Run(runCtx)
+	go sys.cachePolicy.Run(runCtx, CachePolicyInterval)
+	go sys.compactor.Run(runCtx)
+
+	if sys.metrics != nil {
+		if _, err := sys.metrics.Serve(runCtx, *metricsAddr, logger); err != nil {
+			fatal(logger, "failed to start metrics server", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runSimulation(sigCtx, sys)
+	}()
+
+	select {
+	case <-done:
+		if sys.metrics != nil {
+			logger.Info("simulation finished, serving metrics until interrupted")
+			<-sigCtx.Done()
+		}
+	case <-sigCtx.Done():
+		// 시뮬레이션이 멈출 때까지 기다려야 그동안 쓴 로그까지 Stop의 드레인 결과에 포함됨
+		logger.Info("shutdown signal received, stopping simulation")
+		<-done
+	}
+
+	// 진행 중인 배치를 마치고 남은 분석 요청과 로그를 ShutdownTimeout 안에서 비움
+	// (AnalysisWorker가 마지막으로 남긴 User 로그까지 CheckpointWorker가 반영하도록 먼저 멈춤)
+	stopCtx, cancelStop := context.WithTimeout(ctx, ShutdownTimeout)
+	defer cancelStop()
+	if err := sys.analysisWorker.Stop(stopCtx); err != nil {
+		logger.Warn("analysis worker did not drain before shutdown timeout", logging.Err(err))
+	}
+	report, err := sys.checkpointWorker.Stop(stopCtx)
+	if err != nil {
+		// 남은 로그는 다음 실행에서 반영되므로 종료 보고는 계속함
+		logger.Error("checkpoint worker did not drain cleanly", logging.Err(err))
+	}
+	// 다음 Maintain을 기다리던 조회 기록(cache_score, last_accessed_at)도 남김
+	if err := sys.cachePolicy.Flush(stopCtx); err != nil {
+		logger.Warn("failed to flush cache accesses", logging.Err(err))
+	}
+	attrs := []any{
+		slog.Int("committed", report.Committed),
+		slog.Int64("remaining", report.Remaining),
+		slog.Bool("timed_out", report.TimedOut),
+	}
+	if sys.checkpointWorker.Adaptive != nil {
+		decision := sys.checkpointWorker.Adaptive.Decision()
+		attrs = append(attrs, logging.BatchSize(decision.BatchSize), slog.Duration("max_latency", decision.MaxLatency))
+	}
+	logger.Info("shutdown complete", attrs...)
+}
+
+// runSimulation: 쓰기/읽기 성능 비교 시나리오를 차례로 실행합니다.
+func runSimulation(ctx context.Context, sys *system) {
+	logger := sys.logger
+
 	// 임시 User 생성 (업데이트 대상이 필요하므로)
 	user := model.User{Username: "PerformanceTarget"}
-	if err := userRepo.Create(ctx, &user); err != nil {
-		log.Fatalf("Failed to create user: %v", err)
+	if err := sys.userRepo.Create(ctx, &user); fail(ctx, logger, "failed to create user", err) {
+		return
 	}
 
-	fmt.Println("--- DB 서비스 성능 비교 시뮬레이션 시작 ---")
+	logger.Info("simulation started", slog.String("scenario", "write"))
 
 	// --- A. 쓰기 성능 비교 (버퍼링 vs 직접 반영) ---
-	simulateBufferedWrite(ctx, bufferRepo, user.UserID)
-	simulateDirectWrite(ctx, userRepo, user.UserID)
+	simulateBufferedWrite(ctx, logger, sys.bufferRepo, user.UserID)
+	simulateDirectWrite(ctx, logger, sys.userRepo, user.UserID)
+	if interrupted(ctx, logger) {
+		return
+	}
 
 	// Worker의 COMMIT 로직을 실행하여 버퍼를 정리해야 읽기 시나리오를 시작할 수 있습니다.
-	// 실제 Worker의 COMMIT 로직을 여기에 가져와 실행합니다.
-	commitWorker := worker.NewCheckpointWorker(bufferRepo, userRepo, TestWriteCount, time.Minute)
-	commitWorker.ProcessCheckpoint(ctx) // 버퍼의 1000개 로그를 모두 반영
+	drainBuffer(ctx, sys)
 
-	fmt.Println("\n" + "--- B. 읽기 성능 비교 (캐싱 vs 릴레이션 직접 접근) ---")
+	if interrupted(ctx, logger) {
+		return
+	}
+
+	// --- B. 읽기 성능 비교 (캐싱 vs 릴레이션 직접 접근) ---
+	logger.Info("simulation started", slog.String("scenario", "read"))
 
-	// 임시 Cache 데이터 삽입 (Cache Hit 시뮬레이션용)
-	insertMockCache(db, 1) // Restaurant ID 1에 캐시 데이터 삽입
+	// Cache Hit 시뮬레이션용 식당과 리뷰를 쓰기 경로(버퍼 -> Worker)로 넣으면 Worker가 캐시를 채웁니다.
+	seedRestaurant(ctx, sys, user.UserID)
+	if interrupted(ctx, logger) {
+		return
+	}
 
 	// 새로운 함수를 호출하여 평균 결과만 출력합니다.
-	simulateReadScenario(ctx, restaurantService)
+	simulateReadScenario(ctx, sys)
+	if interrupted(ctx, logger) {
+		return
+	}
+
+	// --- C. 반영된 버퍼 로그 정리 (-buffer-retention 0 이면 방금 반영한 로그도 지움) ---
+	if _, err := sys.compactor.Compact(ctx); fail(ctx, logger, "failed to compact buffer log", err) {
+		return
+	}
+}
+
+// interrupted: 종료 신호로 ctx가 취소되었으면 남은 시나리오를 건너뛴다고 기록하고 true를 반환합니다.
+func interrupted(ctx context.Context, logger *slog.Logger) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	logger.Info("simulation interrupted, skipping remaining scenarios")
+	return true
+}
+
+// drainBuffer: 버퍼가 빌 때까지 체크포인트와 신뢰도 분석을 반복 실행하고, 쓰기 합치기로 줄인 쓰기 수를 기록합니다.
+func drainBuffer(ctx context.Context, sys *system) {
+	var committed, applied int
+	defer func() {
+		if applied > 0 {
+			sys.logger.Info("buffer drained",
+				slog.Int("committed", committed),
+				slog.Int("applied", applied),
+				slog.Float64("coalescing_ratio", float64(committed)/float64(applied)),
+			)
+		}
+	}()
+
+	for {
+		result, err := sys.checkpointWorker.Flush(ctx)
+		if fail(ctx, sys.logger, "failed to flush buffer", err) {
+			return
+		}
+		committed += result.Committed
+		applied += result.Applied
+		sys.analysisWorker.ProcessAnalysis(ctx)
+
+		pending, err := sys.bufferRepo.GetPendingLogs(ctx, 1)
+		if fail(ctx, sys.logger, "failed to check pending logs", err) {
+			return
+		}
+		if len(pending) == 0 {
+			return
+		}
+	}
+}
+
+// seedRestaurant: Restaurant 1을 만들고 리뷰를 제출한 뒤 버퍼를 비워 캐시를 채웁니다.
+func seedRestaurant(ctx context.Context, sys *system, ownerID int64) {
+	restaurant := model.Restaurant{
+		Owner:             ownerID,
+		RestaurantName:    "성능측정식당",
+		RestaurantAddress: "서울시 노원구 공릉로 232",
+		LocationRefID:     1,
+		CategoryRefID:     1,
+	}
+	if err := sys.restaurantRepo.Create(ctx, &restaurant); fail(ctx, sys.logger, "failed to create restaurant", err) {
+		return
+	}
+
+	for _, rating := range []float64{4, 5, 4.5, 3.5} {
+		_, err := sys.reviewService.SubmitReview(ctx, &model.Review{
+			RestaurantRefID: restaurant.RestaurantID,
+			UserRefID:       ownerID,
+			Rating:          rating,
+			ReviewContent:   "시뮬레이션 리뷰",
+		})
+		if fail(ctx, sys.logger, "failed to submit review", err) {
+			return
+		}
+	}
+
+	// 반영 전에는 리뷰가 버퍼에 남아 있으므로 요약이 "업데이트 중"으로 보임
+	printSummary(ctx, sys, restaurant.RestaurantID)
+	drainBuffer(ctx, sys)
+	printSummary(ctx, sys, restaurant.RestaurantID)
+}
+
+// printSummary: 식당 요약과 신선도(나이, 반영 대기 중인 로그 수)를 기록합니다.
+func printSummary(ctx context.Context, sys *system, restaurantID int64) {
+	summary, err := sys.restaurantService.FindRestaurantSummary(ctx, restaurantID)
+	if fail(ctx, sys.logger, "failed to read restaurant summary", err) {
+		return
+	}
+	sys.logger.Info("restaurant summary",
+		logging.RestaurantID(restaurantID),
+		slog.Float64("weighted_rating", summary.WeightedRating),
+		slog.Int64("reviews", summary.TotalWeightedReviews),
+		slog.Duration("age", summary.Freshness.Age.Round(time.Millisecond)),
+		slog.Int64("pending_logs", summary.Freshness.PendingLogs),
+		slog.Bool("updating", summary.Freshness.Updating()),
+	)
 }
 
 // simulateBufferedWrite: 1000개의 쓰기 요청을 버퍼에 담는 시간 측정
-func simulateBufferedWrite(ctx context.Context, repo repository.BufferRepository, userID int64) {
+func simulateBufferedWrite(ctx context.Context, logger *slog.Logger, repo repository.BufferRepository, userID int64) {
 	start := time.Now()
 	for i := 0; i < TestWriteCount; i++ {
-		payload := fmt.Sprintf(`{"user_id": %d, "new_score": 0.51, "new_review_count": 1, "new_bias_count": 0}`, userID)
-		bufferLog := model.BufferLog{
-			TransactionType: "UPDATE",
-			TargetTable:     "User",
-			Payload:         payload,
-			TargetRecordID:  userID,
+		bufferLog, err := buffer.NewUserSetLog(userID, 0.51, 1, 0)
+		if fail(ctx, logger, "buffer write failed", err) {
+			return
 		}
-		if err := repo.AddLog(ctx, &bufferLog); err != nil {
-			log.Fatalf("Buffer Write failed: %v", err)
+		if err := repo.AddLog(ctx, &bufferLog); fail(ctx, logger, "buffer write failed", err) {
+			return
 		}
 	}
-	elapsed := time.Since(start)
-	fmt.Printf("[쓰기 시나리오 A - 버퍼링] %d건 AddLog 시간: %s (매우 빠름)\n", TestWriteCount, elapsed)
+	logger.Info("write scenario finished", slog.String("scenario", "buffered"),
+		slog.Int("writes", TestWriteCount), logging.Duration(time.Since(start)))
 }
 
 // simulateDirectWrite: 1000개의 쓰기 요청을 DB에 직접 반영하는 시간 측정
-func simulateDirectWrite(ctx context.Context, repo repository.UserRepository, userID int64) {
+func simulateDirectWrite(ctx context.Context, logger *slog.Logger, repo repository.UserRepository, userID int64) {
 	start := time.Now()
 	for i := 0; i < TestWriteCount; i++ {
 		// 실제로는 AddLog가 아닌, 직접 DB에 영향을 주는 UpdateReliabilityScore를 호출한다고 가정
-		if err := repo.UpdateReliabilityScore(ctx, userID, 0.5, 1, 0); err != nil {
-			log.Fatalf("Direct Write failed: %v", err)
+		if err := repo.UpdateReliabilityScore(ctx, userID, 0.5, 1, 0); fail(ctx, logger, "direct write failed", err) {
+			return
 		}
 	}
-	elapsed := time.Since(start)
-	fmt.Printf("[쓰기 시나리오 B - 직접 반영] %d건 Update 시간: %s (느림 시뮬레이션)\n", TestWriteCount, elapsed)
+	logger.Info("write scenario finished", slog.String("scenario", "direct"),
+		slog.Int("writes", TestWriteCount), logging.Duration(time.Since(start)))
 }
 
-// insertMockCache: 캐시 Hit 시뮬레이션을 위한 데이터 삽입
-func insertMockCache(db *sql.DB, restaurantID int64) {
-	query := `
-       INSERT INTO Cache_Metadata (
-          restaurant_id, location_ref_id, category_ref_id, weighted_rating, 
-          total_weighted_reviews, cache_score, last_cache_updated_at
-       ) VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-	_, err := db.Exec(
-		query,
-		restaurantID, 1, 1, 4.5, 100, 1.0, time.Now().Format("2006-01-02 15:04:05"),
-	)
-	if err != nil {
-		log.Fatalf("Failed to insert mock cache: %v", err)
-	}
-}
+// simulateReadScenario: L1(메모리) vs L2(Cache_Metadata) vs Primary(릴레이션 집계) 읽기 성능 비교 및 평균 시간 기록
+func simulateReadScenario(ctx context.Context, sys *system) {
+	var totalL1Time, totalL2Time, totalPrimaryTime, totalMissTime time.Duration
+
+	for i := 0; i < TestReadCount; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		// 1. L1 시나리오 (Restaurant 1, 메모리 캐시 조회)
+		start := time.Now()
+		sys.cacheRepo.FindCacheByID(ctx, 1)
+		totalL1Time += time.Since(start)
 
-// simulateReadScenario: 캐싱 vs 릴레이션 접근 성능 비교 및 평균 시간 출력 (신규 추가)
-func simulateReadScenario(ctx context.Context, s service.RestaurantService) {
-	var totalCacheHitTime time.Duration
-	var totalCacheMissTime time.Duration
+		// 2. L2 시나리오 (Restaurant 1, Cache_Metadata 직접 조회)
+		start = time.Now()
+		sys.cacheStore.FindCacheByID(ctx, 1)
+		totalL2Time += time.Since(start)
 
-	fmt.Printf("--- 읽기 시뮬레이션 시작 (총 %d회 반복) ---\n", TestReadCount*2)
+		// 3. Primary 시나리오 (Restaurant 1, Restaurant/Review 릴레이션에서 집계)
+		start = time.Now()
+		sys.aggregator.Compute(ctx, 1)
+		totalPrimaryTime += time.Since(start)
 
-	for i := 0; i < TestReadCount; i++ {
-		// 1. 캐시 히트 시나리오 (Restaurant 1)
-		startHit := time.Now()
-		// isHit 등의 반환값은 여기서 사용하지 않음
-		s.FindRestaurantSummary(ctx, 1)
-		totalCacheHitTime += time.Since(startHit)
-
-		// 2. 캐시 미스 시나리오 (Restaurant 99)
-		startMiss := time.Now()
-		s.FindRestaurantSummary(ctx, 99)
-		totalCacheMissTime += time.Since(startMiss)
-	}
-
-	// 평균 계산
-	avgHitTime := totalCacheHitTime / time.Duration(TestReadCount)
-	avgMissTime := totalCacheMissTime / time.Duration(TestReadCount)
-
-	// 최종 출력
-	fmt.Println("--- 읽기 성능 결과 (평균) ---")
-	fmt.Printf("[Read] ✅ CACHE HIT 평균 시간 (Restaurant 1): %s\n", avgHitTime)
-	fmt.Printf("[Read] ❌ CACHE MISS 평균 시간 (Restaurant 99): %s\n", avgMissTime)
+		// 4. 캐시 미스 시나리오 (Restaurant 99, 서비스 경로로 존재하지 않는 식당 조회)
+		start = time.Now()
+		sys.restaurantService.FindRestaurantSummary(ctx, 99)
+		totalMissTime += time.Since(start)
+	}
+
+	// 최종 결과 (경로별 평균 시간)
+	n := time.Duration(TestReadCount)
+	stats := sys.memoryCache.Stats()
+	for _, path := range []struct {
+		name         string
+		restaurantID int64
+		total        time.Duration
+	}{
+		{"l1_memory", 1, totalL1Time},
+		{"l2_cache_metadata", 1, totalL2Time},
+		{"primary_aggregate", 1, totalPrimaryTime},
+		{"cache_miss", 99, totalMissTime},
+	} {
+		sys.logger.Info("read scenario finished", slog.String("path", path.name),
+			logging.RestaurantID(path.restaurantID), slog.Int("reads", TestReadCount), logging.Duration(path.total/n))
+	}
+	sys.logger.Info("l1 cache stats", slog.Int64("hits", stats.Hits), slog.Int64("misses", stats.Misses), slog.Int("entries", stats.Entries))
 }
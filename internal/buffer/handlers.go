@@ -0,0 +1,281 @@
+package buffer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/reliability"
+	"restaurant_db/internal/repository"
+)
+
+// DefaultRegistry: 이 서비스가 버퍼링하는 모든 테이블의 핸들러가 등록된 Registry
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	RegisterDefaults(r)
+	return r
+}
+
+// 기본 Route의 현재 payload 버전 (NewUserSetLog 등 생성 함수가 사용)
+const (
+	UserPayloadVersion       = 2 // 2: mode 필수 (1은 mode가 없으면 set)
+	ReviewPayloadVersion     = 1
+	RestaurantPayloadVersion = 1
+)
+
+// RegisterDefaults: User, Review, Restaurant 핸들러를 등록합니다.
+func RegisterDefaults(r *Registry) {
+	// User UPDATE: AnalysisWorker가 남기는 신뢰도 갱신 (set은 마지막 값이 이기고 delta는 더함)
+	Register(r, "User", model.TransactionUpdate, applyUserUpdate,
+		WithMerge(MergeUserPayloads),
+		WithUpgrade[model.UserReliabilityPayload](1, upgradeUserPayloadV1),
+		WithValidate(validateUserPayload),
+		WithOverlay(overlayUser),
+	)
+
+	// Review INSERT: ReviewService.SubmitReview가 남기는 리뷰 작성
+	Register(r, "Review", model.TransactionInsert, applyReviewInsert,
+		WithRestaurantRef(func(_ model.BufferLog, p model.ReviewPayload) (int64, bool) {
+			return p.RestaurantID, p.RestaurantID != 0
+		}),
+		WithValidate(validateReviewPayload),
+	)
+
+	// Restaurant INSERT/UPDATE/DELETE: 식당 정보 변경 (UPDATE는 행 전체를 덮어쓰므로 마지막 값이 이김)
+	Register(r, "Restaurant", model.TransactionInsert, applyRestaurantInsert,
+		WithValidate(func(_ model.BufferLog, p model.RestaurantPayload) error { return validateRestaurantPayload(p) }),
+	)
+	Register(r, "Restaurant", model.TransactionUpdate, applyRestaurantUpdate,
+		WithMerge(func(_, next model.RestaurantPayload) (model.RestaurantPayload, bool) { return next, true }),
+		WithRestaurantRef(restaurantTarget[model.RestaurantPayload]),
+		WithValidate(func(log model.BufferLog, p model.RestaurantPayload) error {
+			if err := requireTarget(log); err != nil {
+				return err
+			}
+			return validateRestaurantPayload(p)
+		}),
+		WithOverlay(overlayRestaurantUpdate),
+	)
+	Register(r, "Restaurant", model.TransactionDelete, applyRestaurantDelete,
+		WithRestaurantRef(restaurantTarget[struct{}]),
+		WithValidate(func(log model.BufferLog, _ struct{}) error { return requireTarget(log) }),
+		WithOverlay(func(*model.Restaurant, model.BufferLog, struct{}) *model.Restaurant { return nil }),
+	)
+}
+
+// upgradeUserPayloadV1: 버전 1은 mode가 없으면 set으로 반영했으므로 mode를 채워 옮깁니다.
+// 오타 같은 알 수 없는 필드는 버려지면 0으로 반영되므로 현재 버전과 똑같이 거절합니다.
+func upgradeUserPayloadV1(payload []byte) ([]byte, error) {
+	var p model.UserReliabilityPayload
+	if err := decodeStrict(payload, &p); err != nil {
+		return nil, err
+	}
+	if p.Mode == "" {
+		p.Mode = model.UserPayloadModeSet
+	}
+	return json.Marshal(p)
+}
+
+// validateUserPayload: target_record_id와 user_id가 같은 유저를 가리키고, set 모드의 값이 범위 안에 있는지 확인합니다.
+func validateUserPayload(log model.BufferLog, p model.UserReliabilityPayload) error {
+	if p.UserID <= 0 {
+		return errors.New("user_id is required")
+	}
+	if log.TargetRecordID != p.UserID {
+		return fmt.Errorf("target_record_id %d does not match user_id %d", log.TargetRecordID, p.UserID)
+	}
+
+	switch p.Mode {
+	case model.UserPayloadModeSet:
+		if p.NewScore < 0 || p.NewScore > 1 {
+			return fmt.Errorf("new_score %v out of range [0, 1]", p.NewScore)
+		}
+		if p.NewReviewCount < 0 || p.NewBiasCount < 0 || p.NewBiasCount > p.NewReviewCount {
+			return fmt.Errorf("invalid counts (review %d, bias %d)", p.NewReviewCount, p.NewBiasCount)
+		}
+		if p.ScoreDelta != 0 || p.ReviewCountDelta != 0 || p.BiasCountDelta != 0 {
+			return errors.New("delta fields are not allowed in set mode")
+		}
+	case model.UserPayloadModeDelta:
+		if p.NewScore != 0 || p.NewReviewCount != 0 || p.NewBiasCount != 0 {
+			return errors.New("new_* fields are not allowed in delta mode")
+		}
+	default:
+		return fmt.Errorf("unsupported mode %q", p.Mode)
+	}
+	return nil
+}
+
+// validateReviewPayload: 리뷰 대상과 작성자, 내용이 있고 평점과 가중치가 범위 안에 있는지 확인합니다.
+func validateReviewPayload(_ model.BufferLog, p model.ReviewPayload) error {
+	if p.RestaurantID <= 0 || p.UserID <= 0 {
+		return errors.New("restaurant_id and user_id are required")
+	}
+	if p.Rating < model.MinRating || p.Rating > model.MaxRating {
+		return fmt.Errorf("rating %v out of range [%v, %v]", p.Rating, model.MinRating, model.MaxRating)
+	}
+	if p.ReviewContent == "" {
+		return errors.New("review_content is required")
+	}
+	if p.ReliabilityWeight < 0 || p.ReliabilityWeight > 1 {
+		return fmt.Errorf("reliability_weight %v out of range [0, 1]", p.ReliabilityWeight)
+	}
+	return nil
+}
+
+// validateRestaurantPayload: Restaurant 테이블의 NOT NULL 컬럼이 채워져 있는지 확인합니다.
+func validateRestaurantPayload(p model.RestaurantPayload) error {
+	if p.RestaurantName == "" || p.RestaurantAddress == "" {
+		return errors.New("restaurant_name and restaurant_address are required")
+	}
+	if p.Owner <= 0 || p.LocationRefID <= 0 || p.CategoryRefID <= 0 {
+		return errors.New("owner, location_ref_id and category_ref_id are required")
+	}
+	return nil
+}
+
+// requireTarget: 기존 행을 바꾸는 로그는 target_record_id가 있어야 합니다.
+func requireTarget(log model.BufferLog) error {
+	if log.TargetRecordID == 0 {
+		return errors.New("target_record_id is required")
+	}
+	return nil
+}
+
+// applyUserUpdate: mode에 따라 신뢰도 점수를 덮어쓰거나 변화량을 더합니다.
+func applyUserUpdate(ctx context.Context, repos *repository.Repositories, log model.BufferLog, p model.UserReliabilityPayload) error {
+	switch p.Mode {
+	case "", model.UserPayloadModeSet:
+		return repos.User.UpdateReliabilityScore(ctx, p.UserID, p.NewScore, p.NewReviewCount, p.NewBiasCount)
+	case model.UserPayloadModeDelta:
+		return repos.User.AdjustReliability(ctx, p.UserID, p.ScoreDelta, p.ReviewCountDelta, p.BiasCountDelta)
+	default:
+		return Reject(log, "unsupported User payload mode %q", p.Mode)
+	}
+}
+
+// overlayUser: applyUserUpdate와 같은 규칙으로 행을 바꿉니다. (delta의 점수는 AdjustReliability처럼 매번 0 ~ 1로 자름)
+func overlayUser(user *model.User, _ model.BufferLog, p model.UserReliabilityPayload) *model.User {
+	if user == nil {
+		return nil // 없는 유저에 대한 UPDATE는 반영해도 바뀌는 행이 없음
+	}
+	next := *user
+	switch {
+	case isSetMode(p.Mode):
+		next.ReliabilityScore = p.NewScore
+		next.ReviewCount = p.NewReviewCount
+		next.BiasCount = p.NewBiasCount
+	case p.Mode == model.UserPayloadModeDelta:
+		next.ReliabilityScore = reliability.Clamp(next.ReliabilityScore + p.ScoreDelta)
+		next.ReviewCount += p.ReviewCountDelta
+		next.BiasCount += p.BiasCountDelta
+	}
+	return &next
+}
+
+// MergeUserPayloads: 절대값(set)은 나중 값이 이기고, 변화량(delta)은 더하며, set 뒤의 delta는 set에 반영합니다.
+// delta끼리 합친 점수는 마지막에 한 번만 0 ~ 1로 잘리므로, 중간에 범위를 넘는 delta 조합은 순차 반영과 결과가 다를 수 있습니다.
+func MergeUserPayloads(a, b model.UserReliabilityPayload) (model.UserReliabilityPayload, bool) {
+	if a.UserID != b.UserID {
+		return model.UserReliabilityPayload{}, false
+	}
+
+	switch {
+	case isSetMode(b.Mode):
+		return b, true // 마지막 절대값이 앞선 모든 변경을 덮어씀
+	case b.Mode != model.UserPayloadModeDelta:
+		return model.UserReliabilityPayload{}, false // 알 수 없는 mode는 그대로 반영해 거절되게 둠
+	case isSetMode(a.Mode):
+		a.NewScore = reliability.Clamp(a.NewScore + b.ScoreDelta)
+		a.NewReviewCount += b.ReviewCountDelta
+		a.NewBiasCount += b.BiasCountDelta
+		return a, true
+	case a.Mode == model.UserPayloadModeDelta:
+		a.ScoreDelta += b.ScoreDelta
+		a.ReviewCountDelta += b.ReviewCountDelta
+		a.BiasCountDelta += b.BiasCountDelta
+		return a, true
+	default:
+		return model.UserReliabilityPayload{}, false
+	}
+}
+
+func isSetMode(mode string) bool {
+	return mode == "" || mode == model.UserPayloadModeSet
+}
+
+// applyReviewInsert: 리뷰를 만들고(review_id는 여기서 할당됨), 작성자의 신뢰도 재계산은
+// AnalysisWorker가 비동기로 처리하도록 분석 요청만 남깁니다.
+func applyReviewInsert(ctx context.Context, repos *repository.Repositories, _ model.BufferLog, p model.ReviewPayload) error {
+	review := &model.Review{
+		RestaurantRefID:   p.RestaurantID,
+		UserRefID:         p.UserID,
+		Rating:            p.Rating,
+		ReviewContent:     p.ReviewContent,
+		ReliabilityWeight: p.ReliabilityWeight,
+	}
+	if err := repos.Review.Create(ctx, review); err != nil {
+		return err
+	}
+
+	var newBiasCount int64
+	if reliability.IsExtremeRating(review.Rating) {
+		newBiasCount = 1
+	}
+	return repos.Analysis.Create(ctx, &model.ReviewAnalysisLog{
+		ReviewRefID:  review.ReviewID,
+		UserRefID:    review.UserRefID,
+		NewBiasCount: newBiasCount,
+	})
+}
+
+func applyRestaurantInsert(ctx context.Context, repos *repository.Repositories, _ model.BufferLog, p model.RestaurantPayload) error {
+	return repos.Restaurant.Create(ctx, restaurantFromPayload(0, p))
+}
+
+func applyRestaurantUpdate(ctx context.Context, repos *repository.Repositories, log model.BufferLog, p model.RestaurantPayload) error {
+	if log.TargetRecordID == 0 {
+		return Reject(log, "target_record_id is required")
+	}
+	return repos.Restaurant.Update(ctx, restaurantFromPayload(log.TargetRecordID, p))
+}
+
+func applyRestaurantDelete(ctx context.Context, repos *repository.Repositories, log model.BufferLog, _ struct{}) error {
+	if log.TargetRecordID == 0 {
+		return Reject(log, "target_record_id is required")
+	}
+	return repos.Restaurant.Delete(ctx, log.TargetRecordID)
+}
+
+// overlayRestaurantUpdate: applyRestaurantUpdate처럼 payload의 컬럼을 덮어씁니다. (수정 시각은 로그가 쓰인 시각)
+func overlayRestaurantUpdate(restaurant *model.Restaurant, log model.BufferLog, p model.RestaurantPayload) *model.Restaurant {
+	if restaurant == nil {
+		return nil
+	}
+	next := *restaurant
+	next.Owner = p.Owner
+	next.RestaurantName = p.RestaurantName
+	next.RestaurantAddress = p.RestaurantAddress
+	next.LocationRefID = p.LocationRefID
+	next.CategoryRefID = p.CategoryRefID
+	next.LastModifiedAt = log.LogUpdatedAt
+	return &next
+}
+
+func restaurantFromPayload(restaurantID int64, p model.RestaurantPayload) *model.Restaurant {
+	return &model.Restaurant{
+		RestaurantID:      restaurantID,
+		Owner:             p.Owner,
+		RestaurantName:    p.RestaurantName,
+		RestaurantAddress: p.RestaurantAddress,
+		LocationRefID:     p.LocationRefID,
+		CategoryRefID:     p.CategoryRefID,
+	}
+}
+
+// restaurantTarget: target_record_id가 곧 식당 ID인 로그의 RestaurantRef
+func restaurantTarget[P any](log model.BufferLog, _ P) (int64, bool) {
+	return log.TargetRecordID, log.TargetRecordID != 0
+}
@@ -0,0 +1,59 @@
+package buffer
+
+import (
+	"context"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// UserOverlay는 UserRepository.FindByID에 그 유저의 반영되지 않은 User 로그를 겹쳐 돌려주는 읽기 모드입니다.
+// 방금 버퍼에 쓴 변경을 CheckpointWorker를 기다리지 않고 읽을 수 있습니다. (나머지 메서드는 그대로 위임)
+// 행을 먼저 읽고 로그를 읽으므로, 그 사이에 Worker가 반영하면 잠시 예전 값을 볼 수는 있어도 같은 로그를 두 번 겹치지는 않습니다.
+type UserOverlay struct {
+	repository.UserRepository
+	BufferRepo repository.BufferRepository
+	Registry   *Registry // CheckpointWorker와 같은 Registry를 넘겨야 반영 결과와 같아짐
+}
+
+func NewUserOverlay(next repository.UserRepository, bufferRepo repository.BufferRepository, registry *Registry) *UserOverlay {
+	return &UserOverlay{UserRepository: next, BufferRepo: bufferRepo, Registry: registry}
+}
+
+// FindByID: 저장된 행 위에 반영 대기 중인 로그를 log_id 순으로 겹칩니다.
+func (o *UserOverlay) FindByID(ctx context.Context, userID int64) (*model.User, error) {
+	user, err := o.UserRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := o.BufferRepo.GetPendingForRecord(ctx, "User", userID)
+	if err != nil {
+		return nil, err
+	}
+	return Overlay(o.Registry, user, pending)
+}
+
+// RestaurantOverlay는 RestaurantRepository.FindByID에 반영되지 않은 Restaurant UPDATE/DELETE 로그를 겹칩니다.
+// List는 겹치지 않습니다. (UserOverlay와 같은 읽기 순서)
+type RestaurantOverlay struct {
+	repository.RestaurantRepository
+	BufferRepo repository.BufferRepository
+	Registry   *Registry
+}
+
+func NewRestaurantOverlay(next repository.RestaurantRepository, bufferRepo repository.BufferRepository, registry *Registry) *RestaurantOverlay {
+	return &RestaurantOverlay{RestaurantRepository: next, BufferRepo: bufferRepo, Registry: registry}
+}
+
+// FindByID: 삭제 로그가 대기 중이면 nil(없음)을 반환합니다.
+func (o *RestaurantOverlay) FindByID(ctx context.Context, restaurantID int64) (*model.Restaurant, error) {
+	restaurant, err := o.RestaurantRepository.FindByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := o.BufferRepo.GetPendingForRecord(ctx, "Restaurant", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return Overlay(o.Registry, restaurant, pending)
+}
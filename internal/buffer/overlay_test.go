@@ -0,0 +1,88 @@
+package buffer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+	"restaurant_db/internal/worker"
+)
+
+// TestOverlayReadsYourWrites: 반영 대기 중인 로그를 겹친 조회 결과가 CheckpointWorker가 반영한 뒤의 행과 같은지 확인합니다.
+func TestOverlayReadsYourWrites(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	registry := buffer.DefaultRegistry()
+	bufferRepo := repository.NewBufferRepository(conn, repository.WithValidator(registry))
+	userRepo := repository.NewUserRepository(conn)
+	restaurantRepo := repository.NewRestaurantRepository(conn)
+	users := buffer.NewUserOverlay(userRepo, bufferRepo, registry)
+	restaurants := buffer.NewRestaurantOverlay(restaurantRepo, bufferRepo, registry)
+
+	// 1. Given: 유저와 식당 2개, 그리고 아직 반영되지 않은 set -> delta User 로그와 식당 UPDATE/DELETE 로그
+	user := &model.User{Username: "writer"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		r := &model.Restaurant{Owner: user.UserID, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+		if err := restaurantRepo.Create(ctx, r); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	set, _ := buffer.NewUserSetLog(user.UserID, 0.95, 4, 1)
+	delta, _ := buffer.NewUserDeltaLog(user.UserID, 0.1, 1, 0)
+	rename, _ := buffer.NewRestaurantUpdateLog(1, model.RestaurantPayload{
+		Owner: user.UserID, RestaurantName: "새 이름", RestaurantAddress: "새 주소", LocationRefID: 2, CategoryRefID: 1})
+	remove, _ := buffer.NewRestaurantDeleteLog(2)
+	for _, log := range []*model.BufferLog{&set, &delta, &rename, &remove} {
+		if err := bufferRepo.AddLog(ctx, log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+
+	// 2. When: 반영 전에 겹쳐서 조회
+	overlaid, err := users.FindByID(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	renamed, _ := restaurants.FindByID(ctx, 1)
+	removed, _ := restaurants.FindByID(ctx, 2)
+
+	// 3. Then: 점수는 0.95 + 0.1이 1로 잘리고 카운트는 더해지며, 원래 Repository는 아직 예전 값
+	if overlaid.ReliabilityScore != 1 || overlaid.ReviewCount != 5 || overlaid.BiasCount != 1 {
+		t.Errorf("Expected overlaid user (1, 5, 1), got %+v", overlaid)
+	}
+	if persisted, _ := userRepo.FindByID(ctx, user.UserID); persisted.ReviewCount != 0 {
+		t.Errorf("Expected persisted user to be unchanged before checkpoint, got %+v", persisted)
+	}
+	if renamed == nil || renamed.RestaurantName != "새 이름" || renamed.LocationRefID != 2 {
+		t.Errorf("Expected pending rename to be visible, got %+v", renamed)
+	}
+	if removed != nil {
+		t.Errorf("Expected pending delete to hide restaurant 2, got %+v", removed)
+	}
+
+	// Worker가 반영한 뒤에는 겹칠 로그가 없고 결과가 같음
+	w := worker.NewCheckpointWorker(conn, nil, 10, time.Minute, nil)
+	w.Registry = registry
+	if result := w.ProcessCheckpoint(ctx); result.Committed != 4 {
+		t.Fatalf("Expected 4 logs committed, got %+v", result)
+	}
+	applied, _ := userRepo.FindByID(ctx, user.UserID)
+	if applied.ReliabilityScore != overlaid.ReliabilityScore || applied.ReviewCount != overlaid.ReviewCount || applied.BiasCount != overlaid.BiasCount {
+		t.Errorf("Expected overlay %+v to match applied row %+v", overlaid, applied)
+	}
+	if gone, _ := restaurants.FindByID(ctx, 2); gone != nil {
+		t.Errorf("Expected restaurant 2 to be deleted, got %+v", gone)
+	}
+}
@@ -0,0 +1,65 @@
+package buffer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"restaurant_db/internal/model"
+)
+
+// NewLog: payload를 JSON으로 직렬화해 버퍼 로그를 만듭니다. payload 문자열을 직접 조립하지 말고 이 함수(또는 아래의 Route별 함수)를 사용합니다.
+func NewLog[P any](table, transactionType string, targetRecordID int64, version int, payload P) (model.BufferLog, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.BufferLog{}, fmt.Errorf("failed to marshal %s %s payload: %w", transactionType, table, err)
+	}
+	return model.BufferLog{
+		TransactionType: transactionType,
+		TargetTable:     table,
+		Payload:         string(data),
+		PayloadVersion:  version,
+		TargetRecordID:  targetRecordID,
+	}, nil
+}
+
+// NewUserSetLog: 유저의 신뢰도 점수와 카운트를 주어진 값으로 덮어쓰는 로그
+func NewUserSetLog(userID int64, score float64, reviewCount, biasCount int64) (model.BufferLog, error) {
+	return NewLog("User", model.TransactionUpdate, userID, UserPayloadVersion, model.UserReliabilityPayload{
+		Mode:           model.UserPayloadModeSet,
+		UserID:         userID,
+		NewScore:       score,
+		NewReviewCount: reviewCount,
+		NewBiasCount:   biasCount,
+	})
+}
+
+// NewUserDeltaLog: 유저의 신뢰도 점수와 카운트에 변화량을 더하는 로그
+func NewUserDeltaLog(userID int64, scoreDelta float64, reviewCountDelta, biasCountDelta int64) (model.BufferLog, error) {
+	return NewLog("User", model.TransactionUpdate, userID, UserPayloadVersion, model.UserReliabilityPayload{
+		Mode:             model.UserPayloadModeDelta,
+		UserID:           userID,
+		ScoreDelta:       scoreDelta,
+		ReviewCountDelta: reviewCountDelta,
+		BiasCountDelta:   biasCountDelta,
+	})
+}
+
+// NewReviewInsertLog: 리뷰 작성 로그 (review_id는 반영할 때 할당되므로 target_record_id는 0)
+func NewReviewInsertLog(p model.ReviewPayload) (model.BufferLog, error) {
+	return NewLog("Review", model.TransactionInsert, 0, ReviewPayloadVersion, p)
+}
+
+// NewRestaurantInsertLog: 식당 등록 로그
+func NewRestaurantInsertLog(p model.RestaurantPayload) (model.BufferLog, error) {
+	return NewLog("Restaurant", model.TransactionInsert, 0, RestaurantPayloadVersion, p)
+}
+
+// NewRestaurantUpdateLog: 식당 정보 전체를 덮어쓰는 로그
+func NewRestaurantUpdateLog(restaurantID int64, p model.RestaurantPayload) (model.BufferLog, error) {
+	return NewLog("Restaurant", model.TransactionUpdate, restaurantID, RestaurantPayloadVersion, p)
+}
+
+// NewRestaurantDeleteLog: 식당 삭제 로그
+func NewRestaurantDeleteLog(restaurantID int64) (model.BufferLog, error) {
+	return NewLog("Restaurant", model.TransactionDelete, restaurantID, RestaurantPayloadVersion, struct{}{})
+}
@@ -0,0 +1,372 @@
+package buffer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// Route: 로그를 처리할 핸들러를 찾는 키 (대상 테이블 + 트랜잭션 종류)
+type Route struct {
+	Table           string
+	TransactionType string
+}
+
+func (r Route) String() string {
+	return r.TransactionType + " " + r.Table
+}
+
+// RouteOf: 로그의 Route
+func RouteOf(log model.BufferLog) Route {
+	return Route{Table: log.TargetTable, TransactionType: log.TransactionType}
+}
+
+// RejectionError: 다시 시도해도 성공할 수 없는 로그(등록되지 않은 Route 등)임을 나타냅니다.
+// CheckpointWorker는 이 오류를 받으면 재시도하지 않고 바로 Dead Letter로 옮깁니다.
+type RejectionError struct {
+	Route  Route
+	Reason string
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("rejected %s log: %s", e.Route, e.Reason)
+}
+
+// Reject: 핸들러가 영구적인 실패를 알릴 때 사용합니다.
+func Reject(log model.BufferLog, format string, args ...any) error {
+	return &RejectionError{Route: RouteOf(log), Reason: fmt.Sprintf(format, args...)}
+}
+
+// IsRejection: err가 (감싸진) RejectionError인지 확인합니다.
+func IsRejection(err error) bool {
+	var rejection *RejectionError
+	return errors.As(err, &rejection)
+}
+
+// ApplyFunc: 해석된 payload로 로그 하나를 트랜잭션에 묶인 Repository에 반영합니다.
+type ApplyFunc[P any] func(ctx context.Context, repos *repository.Repositories, log model.BufferLog, payload P) error
+
+// Option: Register에 넘기는 선택 기능
+type Option[P any] func(*typedHandler[P])
+
+// WithMerge: 같은 레코드에 대한 연속된 두 로그를 하나로 합치는 규칙을 등록합니다. (쓰기 합치기에 사용)
+// merge는 prev 다음에 next를 반영한 것과 같은 결과의 payload를 반환하거나, 합칠 수 없으면 false를 반환합니다.
+func WithMerge[P any](merge func(prev, next P) (P, bool)) Option[P] {
+	return func(h *typedHandler[P]) { h.mergeFn = merge }
+}
+
+// WithRestaurantRef: 반영된 로그가 어느 식당의 평점/캐시에 영향을 주는지 알려 주는 함수를 등록합니다.
+func WithRestaurantRef[P any](ref func(log model.BufferLog, payload P) (int64, bool)) Option[P] {
+	return func(h *typedHandler[P]) { h.refFn = ref }
+}
+
+// WithUpgrade: from 버전의 payload(JSON)를 from+1 버전으로 바꾸는 함수를 등록합니다.
+// Route의 현재 버전은 등록된 가장 높은 from+1이며(없으면 1), Worker는 예전 버전 로그를 읽을 때 차례로 올려서 해석합니다.
+func WithUpgrade[P any](from int, upgrade func(payload []byte) ([]byte, error)) Option[P] {
+	return func(h *typedHandler[P]) {
+		h.upgrades[from] = upgrade
+		if from+1 > h.version {
+			h.version = from + 1
+		}
+	}
+}
+
+// WithValidate: 해석된 payload의 값(범위, 필수 필드 등)을 검사하는 함수를 등록합니다.
+// AddLog 시점(Registry.Validate)과 반영 직전(Registry.Apply)에 모두 호출됩니다.
+func WithValidate[P any](validate func(log model.BufferLog, payload P) error) Option[P] {
+	return func(h *typedHandler[P]) { h.validateFn = validate }
+}
+
+// WithOverlay: 아직 반영되지 않은 로그를 T 행 위에 겹쳐 보는 함수를 등록합니다. (read-your-writes 조회에 사용)
+// overlay는 로그를 반영한 뒤의 행을 반환해야 하며, 행이 없어지면(DELETE) nil을 반환합니다. row가 nil이면 아직 없는 행입니다.
+func WithOverlay[P, T any](overlay func(row *T, log model.BufferLog, payload P) *T) Option[P] {
+	return func(h *typedHandler[P]) {
+		h.overlayFn = func(row any, log model.BufferLog, payload P) (any, error) {
+			typed, ok := row.(*T)
+			if !ok {
+				return row, fmt.Errorf("%s overlay expects %T, got %T", RouteOf(log), typed, row)
+			}
+			return overlay(typed, log, payload), nil
+		}
+	}
+}
+
+// handler: Route 하나에 등록된 처리기 (payload 타입은 typedHandler가 숨김)
+type handler interface {
+	apply(ctx context.Context, repos *repository.Repositories, log model.BufferLog) error
+	currentVersion() int
+	validate(log model.BufferLog) error
+	mergeable() bool
+	merge(prev, next model.BufferLog) (model.BufferLog, bool)
+	restaurantRef(log model.BufferLog) (int64, bool)
+	overlay(log model.BufferLog, row any) (any, error)
+}
+
+// Registry는 Route별 핸들러 목록입니다. 새로운 테이블을 버퍼링하려면 Worker를 고치는 대신 핸들러를 등록합니다.
+// 등록은 Worker가 돌기 전에 끝내야 합니다. (조회는 동시에 해도 안전하지만 등록과 조회를 동시에 하면 안 됨)
+type Registry struct {
+	handlers map[Route]handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[Route]handler)}
+}
+
+// Register: table/transactionType 로그의 payload를 P로 해석해 apply로 반영하도록 등록합니다. (같은 Route는 덮어씀)
+func Register[P any](r *Registry, table, transactionType string, apply ApplyFunc[P], opts ...Option[P]) {
+	h := &typedHandler[P]{applyFn: apply, version: 1, upgrades: make(map[int]func([]byte) ([]byte, error))}
+	for _, opt := range opts {
+		opt(h)
+	}
+	r.handlers[Route{Table: table, TransactionType: transactionType}] = h
+}
+
+// Routes: 등록된 Route 목록
+func (r *Registry) Routes() []Route {
+	routes := make([]Route, 0, len(r.handlers))
+	for route := range r.handlers {
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// Version: route의 현재 payload 버전 (등록되지 않은 Route는 0)
+func (r *Registry) Version(route Route) int {
+	h, ok := r.handlers[route]
+	if !ok {
+		return 0
+	}
+	return h.currentVersion()
+}
+
+// Validate: AddLog 전에 로그를 검사합니다. BufferRepository의 검증기로 주입해 잘못된 쓰기를 저장 전에 거절합니다.
+// PayloadVersion이 비어 있으면 현재 버전으로 채우며, payload는 알 수 없는 필드 없이 현재 버전의 형태와 정확히 맞아야 합니다.
+// 예전 버전은 이미 저장된 로그를 반영할 때만 올려서 읽으므로, 새 쓰기에는 허용하지 않습니다.
+func (r *Registry) Validate(log *model.BufferLog) error {
+	h, ok := r.handlers[RouteOf(*log)]
+	if !ok {
+		return Reject(*log, "no handler registered")
+	}
+	if log.PayloadVersion == 0 {
+		log.PayloadVersion = h.currentVersion()
+	}
+	if log.PayloadVersion < h.currentVersion() {
+		return Reject(*log, "payload version %d is older than current %d", log.PayloadVersion, h.currentVersion())
+	}
+	return h.validate(*log)
+}
+
+// Apply: 로그의 Route에 등록된 핸들러로 반영합니다. 등록되지 않은 Route는 RejectionError를 반환합니다.
+func (r *Registry) Apply(ctx context.Context, repos *repository.Repositories, log model.BufferLog) error {
+	h, ok := r.handlers[RouteOf(log)]
+	if !ok {
+		return Reject(log, "no handler registered")
+	}
+	return h.apply(ctx, repos, log)
+}
+
+// Mergeable: 로그의 Route에 합치기 규칙이 등록되어 있는지 확인합니다.
+func (r *Registry) Mergeable(log model.BufferLog) bool {
+	h, ok := r.handlers[RouteOf(log)]
+	return ok && h.mergeable()
+}
+
+// Merge: 같은 Route의 두 로그를 합칩니다. 결과 로그는 next의 LogID를 가집니다. (합칠 수 없으면 false)
+func (r *Registry) Merge(prev, next model.BufferLog) (model.BufferLog, bool) {
+	if RouteOf(prev) != RouteOf(next) {
+		return model.BufferLog{}, false
+	}
+	h, ok := r.handlers[RouteOf(next)]
+	if !ok || !h.mergeable() {
+		return model.BufferLog{}, false
+	}
+	return h.merge(prev, next)
+}
+
+// RestaurantRef: 로그가 평점/캐시에 영향을 주는 식당 ID를 반환합니다.
+func (r *Registry) RestaurantRef(log model.BufferLog) (int64, bool) {
+	h, ok := r.handlers[RouteOf(log)]
+	if !ok {
+		return 0, false
+	}
+	return h.restaurantRef(log)
+}
+
+// ErrNoOverlay: 겹쳐 볼 로그의 Route에 WithOverlay가 등록되어 있지 않을 때 반환됩니다.
+var ErrNoOverlay = errors.New("no overlay registered")
+
+// Overlay: 반영되지 않은 logs를 log_id 순서대로 row 위에 겹쳐, Worker가 모두 반영했을 때의 행을 반환합니다. (nil이면 삭제됨)
+// Worker가 결국 거절하거나 해석하지 못할 로그(등록되지 않은 Route, 잘못된 payload)는 반영되지 않으므로 건너뜁니다.
+func Overlay[T any](r *Registry, row *T, logs []model.BufferLog) (*T, error) {
+	for _, log := range logs {
+		h, ok := r.handlers[RouteOf(log)]
+		if !ok {
+			continue
+		}
+		next, err := h.overlay(log, row)
+		if err != nil {
+			return nil, err
+		}
+		row = next.(*T)
+	}
+	return row, nil
+}
+
+// typedHandler: payload 타입 P에 대한 handler 구현
+type typedHandler[P any] struct {
+	applyFn    ApplyFunc[P]
+	mergeFn    func(prev, next P) (P, bool)
+	refFn      func(log model.BufferLog, payload P) (int64, bool)
+	validateFn func(log model.BufferLog, payload P) error
+	overlayFn  func(row any, log model.BufferLog, payload P) (any, error)
+
+	version  int                                  // 현재 payload 버전
+	upgrades map[int]func([]byte) ([]byte, error) // from 버전 -> from+1 버전
+}
+
+func (h *typedHandler[P]) currentVersion() int {
+	return h.version
+}
+
+// decode: 예전 버전 payload를 현재 버전으로 올린 뒤, 알 수 없는 필드를 허용하지 않고 P로 해석합니다.
+// PayloadVersion 0은 검증기 없이 버전을 정하지 않고 쓴 로그이므로 현재 버전으로 취급합니다.
+// (버전이 도입되기 전에 쓰인 로그는 마이그레이션에서 1로 채워짐) 현재보다 높은 버전은 거절합니다.
+func (h *typedHandler[P]) decode(log model.BufferLog) (P, error) {
+	var payload P
+
+	version := log.PayloadVersion
+	if version == 0 {
+		version = h.version
+	}
+	if version > h.version {
+		return payload, Reject(log, "unsupported payload version %d (current %d)", version, h.version)
+	}
+
+	raw := []byte(log.Payload)
+	for ; version < h.version; version++ {
+		upgrade, ok := h.upgrades[version]
+		if !ok {
+			return payload, Reject(log, "no upgrade from payload version %d", version)
+		}
+		upgraded, err := upgrade(raw)
+		if err != nil {
+			return payload, fmt.Errorf("failed to upgrade %s payload from version %d: %w", RouteOf(log), version, err)
+		}
+		raw = upgraded
+	}
+
+	if err := decodeStrict(raw, &payload); err != nil {
+		return payload, fmt.Errorf("failed to unmarshal %s payload: %w", RouteOf(log), err)
+	}
+	return payload, nil
+}
+
+// decodeStrict: raw가 알 수 없는 필드나 뒤따르는 데이터 없이 v의 형태와 정확히 맞을 때만 해석합니다. (업그레이드 함수에서도 사용)
+func decodeStrict(raw []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON object")
+	}
+	return nil
+}
+
+// validate: payload를 해석하고 등록된 검증 함수를 실행합니다. 해석 실패도 거절로 바꿉니다. (저장 전에는 다시 시도할 이유가 없음)
+func (h *typedHandler[P]) validate(log model.BufferLog) error {
+	payload, err := h.decode(log)
+	if err != nil {
+		if IsRejection(err) {
+			return err
+		}
+		return Reject(log, "%v", err)
+	}
+	return h.check(log, payload)
+}
+
+// check: 검증 함수의 실패를 RejectionError로 감쌉니다.
+func (h *typedHandler[P]) check(log model.BufferLog, payload P) error {
+	if h.validateFn == nil {
+		return nil
+	}
+	if err := h.validateFn(log, payload); err != nil {
+		if IsRejection(err) {
+			return err
+		}
+		return Reject(log, "%v", err)
+	}
+	return nil
+}
+
+func (h *typedHandler[P]) apply(ctx context.Context, repos *repository.Repositories, log model.BufferLog) error {
+	payload, err := h.decode(log)
+	if err != nil {
+		return err
+	}
+	if err := h.check(log, payload); err != nil {
+		return err
+	}
+	return h.applyFn(ctx, repos, log, payload)
+}
+
+func (h *typedHandler[P]) mergeable() bool {
+	return h.mergeFn != nil
+}
+
+// merge: 두 로그를 현재 버전으로 해석해 합치므로, 결과 로그는 현재 버전의 payload를 가집니다.
+func (h *typedHandler[P]) merge(prev, next model.BufferLog) (model.BufferLog, bool) {
+	a, err := h.decode(prev)
+	if err != nil {
+		return model.BufferLog{}, false
+	}
+	b, err := h.decode(next)
+	if err != nil {
+		return model.BufferLog{}, false
+	}
+	merged, ok := h.mergeFn(a, b)
+	if !ok {
+		return model.BufferLog{}, false
+	}
+
+	payload, err := json.Marshal(merged)
+	if err != nil {
+		return model.BufferLog{}, false
+	}
+	out := next
+	out.Payload = string(payload)
+	out.PayloadVersion = h.version
+	return out, true
+}
+
+func (h *typedHandler[P]) restaurantRef(log model.BufferLog) (int64, bool) {
+	if h.refFn == nil {
+		return 0, false
+	}
+	payload, err := h.decode(log)
+	if err != nil {
+		return 0, false
+	}
+	return h.refFn(log, payload)
+}
+
+// overlay: 해석이나 검증에 실패한 로그는 Worker도 반영하지 못하므로 row를 그대로 돌려줍니다.
+func (h *typedHandler[P]) overlay(log model.BufferLog, row any) (any, error) {
+	if h.overlayFn == nil {
+		return row, fmt.Errorf("%w: %s", ErrNoOverlay, RouteOf(log))
+	}
+	payload, err := h.decode(log)
+	if err != nil {
+		return row, nil
+	}
+	if err := h.check(log, payload); err != nil {
+		return row, nil
+	}
+	return h.overlayFn(row, log, payload)
+}
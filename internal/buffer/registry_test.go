@@ -0,0 +1,180 @@
+package buffer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// TestRegistryDispatchesByRoute: 등록된 (테이블, 트랜잭션 종류)로 타입이 있는 payload가 전달되고,
+// 등록되지 않은 조합은 RejectionError로 거절되는지 확인합니다.
+func TestRegistryDispatchesByRoute(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	repos := repository.NewRepositories(conn)
+
+	// 1. Given: 기본 핸들러 + Category INSERT 핸들러를 새로 등록
+	type categoryPayload struct {
+		Name string `json:"name"`
+	}
+	var applied []string
+	registry := buffer.DefaultRegistry()
+	buffer.Register(registry, "Category", model.TransactionInsert,
+		func(_ context.Context, _ *repository.Repositories, _ model.BufferLog, p categoryPayload) error {
+			applied = append(applied, p.Name)
+			return nil
+		},
+	)
+
+	// 2. When & Then: 새 핸들러로 전달
+	log := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Category", Payload: `{"name": "한식"}`}
+	if err := registry.Apply(ctx, repos, log); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0] != "한식" {
+		t.Errorf("Expected Category handler to receive typed payload, got %v", applied)
+	}
+
+	// 기본 핸들러: Restaurant INSERT -> UPDATE는 실제 테이블에 반영
+	insert := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Restaurant",
+		Payload: `{"owner": 1, "restaurant_name": "처음", "restaurant_address": "주소", "location_ref_id": 1, "category_ref_id": 1}`}
+	update := model.BufferLog{TransactionType: model.TransactionUpdate, TargetTable: "Restaurant", TargetRecordID: 1,
+		Payload: `{"owner": 1, "restaurant_name": "바뀐 이름", "restaurant_address": "주소", "location_ref_id": 2, "category_ref_id": 1}`}
+	for _, log := range []model.BufferLog{insert, update} {
+		if err := registry.Apply(ctx, repos, log); err != nil {
+			t.Fatalf("Apply %s failed: %v", buffer.RouteOf(log), err)
+		}
+	}
+	restaurant, _ := repos.Restaurant.FindByID(ctx, 1)
+	if restaurant == nil || restaurant.RestaurantName != "바뀐 이름" || restaurant.LocationRefID != 2 {
+		t.Errorf("Expected restaurant to be inserted then updated, got %+v", restaurant)
+	}
+	if id, ok := registry.RestaurantRef(update); !ok || id != 1 {
+		t.Errorf("Expected Restaurant UPDATE to reference restaurant 1, got %d, %v", id, ok)
+	}
+
+	// 등록되지 않은 조합은 거절
+	for _, log := range []model.BufferLog{
+		{TransactionType: model.TransactionDelete, TargetTable: "Category", Payload: `{}`},
+		{TransactionType: "UPSERT", TargetTable: "User", Payload: `{}`},
+	} {
+		if err := registry.Apply(ctx, repos, log); !buffer.IsRejection(err) {
+			t.Errorf("Expected %s to be rejected, got %v", buffer.RouteOf(log), err)
+		}
+	}
+}
+
+// TestRegistryMerge: 합치기 규칙은 같은 Route끼리만 적용되는지 확인합니다.
+func TestRegistryMerge(t *testing.T) {
+	registry := buffer.DefaultRegistry()
+
+	set := model.BufferLog{LogID: 1, TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: 7, PayloadVersion: 1,
+		Payload: `{"user_id": 7, "new_score": 0.4, "new_review_count": 2, "new_bias_count": 0}`}
+	delta := model.BufferLog{LogID: 2, TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: 7,
+		Payload: `{"mode": "delta", "user_id": 7, "score_delta": 0.1, "review_count_delta": 1}`}
+
+	merged, ok := registry.Merge(set, delta)
+	if !ok || merged.LogID != 2 {
+		t.Fatalf("Expected set + delta to merge into log 2, got %+v, %v", merged, ok)
+	}
+	if merged.Payload != `{"mode":"set","user_id":7,"new_score":0.5,"new_review_count":3,"new_bias_count":0}` {
+		t.Errorf("Unexpected merged payload: %s", merged.Payload)
+	}
+
+	review := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Review", Payload: `{}`}
+	if registry.Mergeable(review) {
+		t.Errorf("Expected Review INSERT to have no merge rule")
+	}
+	if _, ok := registry.Merge(set, review); ok {
+		t.Errorf("Expected logs of different routes not to merge")
+	}
+}
+
+// TestValidatedAddLog: 검증기를 주입한 BufferRepository는 잘못된 payload를 저장 전에 거절하고,
+// 버전 1로 저장된 예전 User 로그는 반영할 때 현재 버전으로 올라가는지 확인합니다.
+func TestValidatedAddLog(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	registry := buffer.DefaultRegistry()
+	bufferRepo := repository.NewBufferRepository(conn, repository.WithValidator(registry))
+	user := &model.User{Username: "versioned"}
+	if err := repository.NewUserRepository(conn).Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	// 1. Given & When: 오타(new_scor), 범위 밖 값, 알 수 없는 Route
+	invalid := []model.BufferLog{
+		{TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: user.UserID,
+			Payload: fmt.Sprintf(`{"mode": "set", "user_id": %d, "new_scor": 0.9}`, user.UserID)},
+		{TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: user.UserID,
+			Payload: fmt.Sprintf(`{"mode": "set", "user_id": %d, "new_score": 1.5}`, user.UserID)},
+		{TransactionType: model.TransactionInsert, TargetTable: "Location", Payload: `{}`},
+	}
+	for _, log := range invalid {
+		// 3. Then: ErrInvalidLog로 거절되고 버퍼에는 남지 않음
+		if err := bufferRepo.AddLog(ctx, &log); !errors.Is(err, repository.ErrInvalidLog) || !buffer.IsRejection(err) {
+			t.Errorf("Expected %s to be refused, got %v", log.Payload, err)
+		}
+	}
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 10); len(pending) != 0 {
+		t.Fatalf("Expected no buffered logs, got %d", len(pending))
+	}
+
+	// 생성 함수로 만든 로그는 현재 버전으로 저장됨
+	valid, err := buffer.NewUserSetLog(user.UserID, 0.7, 1, 0)
+	if err != nil {
+		t.Fatalf("NewUserSetLog failed: %v", err)
+	}
+	if err := bufferRepo.AddLog(ctx, &valid); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+	if valid.PayloadVersion != buffer.UserPayloadVersion {
+		t.Errorf("Expected payload version %d, got %d", buffer.UserPayloadVersion, valid.PayloadVersion)
+	}
+
+	// 이미 저장된 버전 1 payload(mode 없음)는 올려서 반영되지만, 새 쓰기는 현재 버전만 받음
+	repos := repository.NewRepositories(conn)
+	legacy := model.BufferLog{TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: user.UserID, PayloadVersion: 1,
+		Payload: fmt.Sprintf(`{"user_id": %d, "new_score": 0.4, "new_review_count": 2, "new_bias_count": 1}`, user.UserID)}
+	if err := bufferRepo.AddLog(ctx, &legacy); !errors.Is(err, repository.ErrInvalidLog) {
+		t.Errorf("Expected a new write below the current version to be refused, got %v", err)
+	}
+	if err := registry.Apply(ctx, repos, legacy); err != nil {
+		t.Fatalf("Expected legacy payload to be upgraded, got %v", err)
+	}
+
+	// 버전 1이어도 오타(new_scor)는 버려지지 않고 실패함 (0으로 반영되면 안 됨)
+	typo := legacy
+	typo.Payload = fmt.Sprintf(`{"user_id": %d, "new_scor": 0.2, "new_review_count": 2, "new_bias_count": 1}`, user.UserID)
+	if err := registry.Apply(ctx, repos, typo); err == nil {
+		t.Errorf("Expected unknown field in a version 1 payload to fail")
+	}
+	if err := registry.Validate(&typo); !buffer.IsRejection(err) {
+		t.Errorf("Expected unknown field in a version 1 payload to be rejected, got %v", err)
+	}
+	found, _ := repos.User.FindByID(ctx, user.UserID)
+	if found.ReliabilityScore != 0.4 || found.ReviewCount != 2 || found.BiasCount != 1 {
+		t.Errorf("Expected legacy payload to be applied as set, got %+v", found)
+	}
+
+	future := valid
+	future.PayloadVersion = buffer.UserPayloadVersion + 1
+	if err := registry.Apply(ctx, repos, future); !buffer.IsRejection(err) {
+		t.Errorf("Expected unknown payload version to be rejected, got %v", err)
+	}
+}
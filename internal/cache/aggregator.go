@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// rebuildPageSize: 전체 재구성 시 한 번에 읽어오는 식당 수
+const rebuildPageSize = 100
+
+// RatingAggregator는 Review와 Restaurant 릴레이션으로부터 식당별 가중 평점을 계산해 Cache_Metadata를 채웁니다.
+type RatingAggregator struct {
+	RestaurantRepo repository.RestaurantRepository
+	ReviewRepo     repository.ReviewRepository
+	CacheRepo      repository.CacheRepository
+	// InitialScore: Refresh가 새로 만드는 캐시 행의 cache_score (이미 있는 행의 점수는 바꾸지 않음)
+	InitialScore float64
+}
+
+func NewRatingAggregator(
+	restaurantRepo repository.RestaurantRepository,
+	reviewRepo repository.ReviewRepository,
+	cacheRepo repository.CacheRepository,
+) *RatingAggregator {
+	return &RatingAggregator{
+		RestaurantRepo: restaurantRepo,
+		ReviewRepo:     reviewRepo,
+		CacheRepo:      cacheRepo,
+	}
+}
+
+// Compute: 식당 하나의 가중 평점 요약을 Primary 릴레이션에서 계산만 합니다. (캐시에 쓰지 않음)
+// 식당이 존재하지 않으면 nil을 반환합니다.
+func (a *RatingAggregator) Compute(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
+	restaurant, err := a.RestaurantRepo.FindByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, nil
+	}
+
+	rating, count, err := a.ReviewRepo.WeightedRating(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CacheMetadata{
+		RestaurantID:         restaurant.RestaurantID,
+		LocationRefID:        restaurant.LocationRefID,
+		CategoryRefID:        restaurant.CategoryRefID,
+		WeightedRating:       rating,
+		TotalWeightedReviews: count,
+		LastCacheUpdatedAt:   time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// Refresh: 식당 하나의 가중 평점을 다시 계산해 캐시 행을 upsert하고 그 결과를 반환합니다.
+// 식당이 더 이상 존재하지 않으면 남아 있는 캐시 행을 지우고 nil을 반환합니다.
+func (a *RatingAggregator) Refresh(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
+	summary, err := a.Compute(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		if err := a.CacheRepo.Delete(ctx, restaurantID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	summary.CacheScore = a.InitialScore // 새 행일 때만 쓰임
+	if err := a.CacheRepo.Upsert(ctx, summary); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// RebuildAll: 모든 식당의 캐시 행을 다시 계산합니다. 갱신된 식당 수를 반환합니다.
+func (a *RatingAggregator) RebuildAll(ctx context.Context) (int, error) {
+	var refreshed int
+
+	for offset := 0; ; offset += rebuildPageSize {
+		restaurants, err := a.RestaurantRepo.List(ctx, rebuildPageSize, offset)
+		if err != nil {
+			return refreshed, err
+		}
+
+		for _, restaurant := range restaurants {
+			if _, err := a.Refresh(ctx, restaurant.RestaurantID); err != nil {
+				return refreshed, fmt.Errorf("failed to rebuild cache for restaurant %d: %w", restaurant.RestaurantID, err)
+			}
+			refreshed++
+		}
+
+		if len(restaurants) < rebuildPageSize {
+			return refreshed, nil
+		}
+	}
+}
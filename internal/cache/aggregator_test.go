@@ -0,0 +1,125 @@
+package cache_test
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	return conn
+}
+
+// createRestaurant: 테스트용 식당과 (평점, 가중치) 리뷰들을 바로 릴레이션에 넣습니다.
+func createRestaurant(t *testing.T, conn *sql.DB, reviews ...[2]float64) int64 {
+	ctx := context.Background()
+	restaurant := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 2, CategoryRefID: 3}
+	if err := repository.NewRestaurantRepository(conn).Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	reviewRepo := repository.NewReviewRepository(conn)
+	for _, r := range reviews {
+		review := model.Review{RestaurantRefID: restaurant.RestaurantID, UserRefID: 1, Rating: r[0], ReliabilityWeight: r[1], ReviewContent: "리뷰"}
+		if err := reviewRepo.Create(ctx, &review); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	return restaurant.RestaurantID
+}
+
+func newAggregator(conn *sql.DB) (*cache.RatingAggregator, repository.CacheRepository) {
+	cacheRepo := repository.NewCacheRepository(conn)
+	return cache.NewRatingAggregator(
+		repository.NewRestaurantRepository(conn),
+		repository.NewReviewRepository(conn),
+		cacheRepo,
+	), cacheRepo
+}
+
+// TestRefreshComputesWeightedRating: 신뢰도 가중 평균과 위치/카테고리 복사, cache_score 보존을 확인합니다.
+func TestRefreshComputesWeightedRating(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+	aggregator, cacheRepo := newAggregator(conn)
+
+	// (5 * 1.0 + 2 * 0.5) / 1.5 = 4.0, 가중치 0인 리뷰는 제외
+	restaurantID := createRestaurant(t, conn, [2]float64{5, 1.0}, [2]float64{2, 0.5}, [2]float64{1, 0})
+
+	summary, err := aggregator.Refresh(ctx, restaurantID)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if math.Abs(summary.WeightedRating-4.0) > 1e-9 || summary.TotalWeightedReviews != 2 {
+		t.Errorf("Expected 4.0 over 2 reviews, got %.3f over %d", summary.WeightedRating, summary.TotalWeightedReviews)
+	}
+
+	cached, err := cacheRepo.FindCacheByID(ctx, restaurantID)
+	if err != nil {
+		t.Fatalf("FindCacheByID failed: %v", err)
+	}
+	if cached == nil || cached.LocationRefID != 2 || cached.CategoryRefID != 3 {
+		t.Fatalf("Expected cache row with location/category copied, got %+v", cached)
+	}
+
+	// 캐시 정책이 올린 cache_score는 재계산 후에도 유지되어야 함
+	if _, err := conn.Exec("UPDATE Cache_Metadata SET cache_score = 7 WHERE restaurant_id = ?", restaurantID); err != nil {
+		t.Fatalf("Failed to set cache_score: %v", err)
+	}
+	if _, err := aggregator.Refresh(ctx, restaurantID); err != nil {
+		t.Fatalf("second Refresh failed: %v", err)
+	}
+	cached, _ = cacheRepo.FindCacheByID(ctx, restaurantID)
+	if cached.CacheScore != 7 {
+		t.Errorf("Expected cache_score 7 to be preserved, got %.0f", cached.CacheScore)
+	}
+
+	// 식당이 삭제되면 캐시 행도 제거
+	if err := repository.NewRestaurantRepository(conn).Delete(ctx, restaurantID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	summary, err = aggregator.Refresh(ctx, restaurantID)
+	if err != nil || summary != nil {
+		t.Fatalf("Expected nil summary for deleted restaurant, got %+v, %v", summary, err)
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, restaurantID); cached != nil {
+		t.Errorf("Expected cache row to be removed, got %+v", cached)
+	}
+}
+
+// TestRebuildAll: 전체 재구성이 모든 식당의 캐시 행을 만드는지 확인합니다.
+func TestRebuildAll(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	aggregator, _ := newAggregator(conn)
+
+	for i := 0; i < 3; i++ {
+		createRestaurant(t, conn, [2]float64{4, 0.5})
+	}
+
+	refreshed, err := aggregator.RebuildAll(context.Background())
+	if err != nil {
+		t.Fatalf("RebuildAll failed: %v", err)
+	}
+	if refreshed != 3 {
+		t.Errorf("Expected 3 restaurants refreshed, got %d", refreshed)
+	}
+
+	var rows int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Cache_Metadata").Scan(&rows); err != nil {
+		t.Fatalf("Failed to count cache rows: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("Expected 3 cache rows, got %d", rows)
+	}
+}
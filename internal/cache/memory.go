@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// MemoryCache는 CacheRepository 앞에 두는 프로세스 내 L1 캐시입니다. (L2: Cache_Metadata, Primary: Restaurant/Review)
+// 용량(LRU)과 TTL로 크기와 신선도를 제한하고, 미스이면 다음 단계(Next)로 내려갑니다.
+// Upsert/Delete/퇴출은 Next에 먼저 쓰고 L1 항목을 무효화하므로, Worker가 식당을 다시 계산하면 다음 조회는 새 값을 읽습니다.
+type MemoryCache struct {
+	Next     repository.CacheRepository
+	Capacity int
+	TTL      time.Duration
+
+	mu    sync.Mutex
+	order *list.List // 앞쪽이 가장 최근에 사용된 항목
+	items map[int64]*list.Element
+
+	// generations: 식당별 무효화 횟수, epoch: Purge 횟수 (Purge는 generations도 비움)
+	// 미스 때 읽어 둔 세대가 Next에서 읽는 동안 바뀌었으면, 읽은 값이 무효화 이전 값일 수 있으므로 L1에 넣지 않음
+	generations map[int64]uint64
+	epoch       uint64
+
+	hits   int64
+	misses int64
+}
+
+// generation: 미스 시점의 세대 (put 직전 값과 같아야 L1에 넣음)
+type generation struct {
+	epoch uint64
+	key   uint64
+}
+
+// memoryEntry: L1에 저장되는 캐시 행과 만료 시각
+type memoryEntry struct {
+	cache     model.CacheMetadata
+	expiresAt time.Time
+}
+
+// MemoryStats: L1 캐시의 누적 히트/미스와 현재 항목 수
+type MemoryStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
+
+// NewMemoryCache: capacity가 0 이하이면 크기 제한이 없고, ttl이 0 이하이면 만료되지 않습니다.
+func NewMemoryCache(next repository.CacheRepository, capacity int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		Next:        next,
+		Capacity:    capacity,
+		TTL:         ttl,
+		order:       list.New(),
+		items:       make(map[int64]*list.Element),
+		generations: make(map[int64]uint64),
+	}
+}
+
+// FindCacheByID: L1에 살아 있는 항목이 있으면 바로 반환하고, 없으면 Next에서 읽어 L1에 채웁니다.
+// Next에서 읽는 동안 같은 식당이 무효화되었으면(Upsert/Delete/Invalidate/Purge) 읽은 값을 반환만 하고 L1에는 넣지 않습니다.
+func (m *MemoryCache) FindCacheByID(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
+	cached, gen, ok := m.get(restaurantID)
+	if ok {
+		return cached, nil
+	}
+
+	cached, err := m.Next.FindCacheByID(ctx, restaurantID)
+	if err != nil || cached == nil {
+		return cached, err
+	}
+
+	m.put(cached, gen)
+	return cached, nil
+}
+
+// Upsert: Next에 쓴 뒤 L1 항목을 무효화합니다. (cache_score는 Next가 관리하므로 다음 조회 때 다시 읽음)
+func (m *MemoryCache) Upsert(ctx context.Context, cache *model.CacheMetadata) error {
+	if err := m.Next.Upsert(ctx, cache); err != nil {
+		return err
+	}
+	m.Invalidate(cache.RestaurantID)
+	return nil
+}
+
+// Delete: Next에서 지우고 L1 항목도 무효화합니다.
+func (m *MemoryCache) Delete(ctx context.Context, restaurantID int64) error {
+	if err := m.Next.Delete(ctx, restaurantID); err != nil {
+		return err
+	}
+	m.Invalidate(restaurantID)
+	return nil
+}
+
+// IncrementScore: 히트마다 호출되므로 무효화하지 않고 L1 항목의 점수도 같이 올립니다.
+func (m *MemoryCache) IncrementScore(ctx context.Context, restaurantID int64, delta float64) error {
+	if err := m.Next.IncrementScore(ctx, restaurantID, delta); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if elem, ok := m.items[restaurantID]; ok {
+		elem.Value.(*memoryEntry).cache.CacheScore += delta
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// DecayScores: Next와 L1 항목의 점수를 같은 비율로 감쇠시킵니다.
+func (m *MemoryCache) DecayScores(ctx context.Context, factor float64) error {
+	if err := m.Next.DecayScores(ctx, factor); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for _, elem := range m.items {
+		elem.Value.(*memoryEntry).cache.CacheScore *= factor
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// EvictBelow: 어떤 행이 내려갔는지 알 수 없으므로 퇴출이 있었으면 L1 전체를 비웁니다.
+func (m *MemoryCache) EvictBelow(ctx context.Context, threshold float64) (int64, error) {
+	evicted, err := m.Next.EvictBelow(ctx, threshold)
+	if evicted > 0 {
+		m.Purge()
+	}
+	return evicted, err
+}
+
+// EvictLowest: EvictBelow와 같은 이유로 퇴출이 있었으면 L1 전체를 비웁니다.
+func (m *MemoryCache) EvictLowest(ctx context.Context, n int64) (int64, error) {
+	evicted, err := m.Next.EvictLowest(ctx, n)
+	if evicted > 0 {
+		m.Purge()
+	}
+	return evicted, err
+}
+
+// Count: 캐시 정책은 Cache_Metadata 행 수를 기준으로 하므로 Next에 그대로 위임합니다.
+func (m *MemoryCache) Count(ctx context.Context) (int64, error) {
+	return m.Next.Count(ctx)
+}
+
+// Invalidate: 식당 하나의 L1 항목을 제거합니다.
+func (m *MemoryCache) Invalidate(restaurantID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.generations[restaurantID]++
+	if elem, ok := m.items[restaurantID]; ok {
+		m.order.Remove(elem)
+		delete(m.items, restaurantID)
+	}
+}
+
+// Purge: L1의 모든 항목을 제거합니다.
+func (m *MemoryCache) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.epoch++
+	m.generations = make(map[int64]uint64)
+	m.order.Init()
+	m.items = make(map[int64]*list.Element)
+}
+
+// Stats: 현재까지의 L1 히트/미스와 항목 수
+func (m *MemoryCache) Stats() MemoryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return MemoryStats{Hits: m.hits, Misses: m.misses, Entries: len(m.items)}
+}
+
+// get: 만료되지 않은 항목의 복사본을 반환하고 최근 사용으로 옮깁니다. (만료된 항목은 제거)
+// 미스이면 그 시점의 세대를 함께 반환합니다.
+func (m *MemoryCache) get(restaurantID int64) (*model.CacheMetadata, generation, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gen := generation{epoch: m.epoch, key: m.generations[restaurantID]}
+	elem, ok := m.items[restaurantID]
+	if !ok {
+		m.misses++
+		return nil, gen, false
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if m.TTL > 0 && time.Now().After(entry.expiresAt) {
+		m.order.Remove(elem)
+		delete(m.items, restaurantID)
+		m.misses++
+		return nil, gen, false
+	}
+
+	m.order.MoveToFront(elem)
+	m.hits++
+	cached := entry.cache
+	return &cached, gen, true
+}
+
+// put: 세대가 gen 그대로이면 항목을 L1에 넣고, 용량을 넘으면 가장 오래 사용되지 않은 항목부터 내립니다.
+func (m *MemoryCache) put(cache *model.CacheMetadata, gen generation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if gen != (generation{epoch: m.epoch, key: m.generations[cache.RestaurantID]}) {
+		return // 읽는 동안 무효화됨
+	}
+
+	entry := &memoryEntry{cache: *cache}
+	if m.TTL > 0 {
+		entry.expiresAt = time.Now().Add(m.TTL)
+	}
+
+	if elem, ok := m.items[cache.RestaurantID]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+		return
+	}
+	m.items[cache.RestaurantID] = m.order.PushFront(entry)
+
+	for m.Capacity > 0 && m.order.Len() > m.Capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryEntry).cache.RestaurantID)
+	}
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// TestMemoryCacheServesAndInvalidates: L1 히트, 재계산 시 무효화, LRU 용량 제한, TTL 만료를 확인합니다.
+func TestMemoryCacheServesAndInvalidates(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	memory := cache.NewMemoryCache(repository.NewCacheRepository(conn), 1, time.Hour)
+	aggregator := cache.NewRatingAggregator(
+		repository.NewRestaurantRepository(conn),
+		repository.NewReviewRepository(conn),
+		memory,
+	)
+
+	first := createRestaurant(t, conn, [2]float64{4, 1})
+	second := createRestaurant(t, conn, [2]float64{2, 1})
+	for _, id := range []int64{first, second} {
+		if _, err := aggregator.Refresh(ctx, id); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	// 1. 첫 조회는 L2에서 채우고, 두 번째 조회는 L1 히트
+	for i := 0; i < 2; i++ {
+		if cached, err := memory.FindCacheByID(ctx, first); err != nil || cached == nil {
+			t.Fatalf("FindCacheByID failed: %v, %+v", err, cached)
+		}
+	}
+	if stats := memory.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Expected 1 hit, 1 miss, 1 entry, got %+v", stats)
+	}
+
+	// 2. L2만 바꾸면 L1은 이전 값을 돌려주지만, 재계산(Upsert)은 L1을 무효화
+	if _, err := conn.Exec("UPDATE Review SET rating = 5 WHERE restaurant_ref_id = ?", first); err != nil {
+		t.Fatalf("Failed to update review: %v", err)
+	}
+	if cached, _ := memory.FindCacheByID(ctx, first); cached.WeightedRating != 4 {
+		t.Errorf("Expected stale L1 value 4 before refresh, got %.2f", cached.WeightedRating)
+	}
+	if _, err := aggregator.Refresh(ctx, first); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if cached, _ := memory.FindCacheByID(ctx, first); cached.WeightedRating != 5 {
+		t.Errorf("Expected refreshed value 5 after invalidation, got %.2f", cached.WeightedRating)
+	}
+
+	// 3. 용량 1이므로 다른 식당을 읽으면 앞선 항목이 밀려남
+	if _, err := memory.FindCacheByID(ctx, second); err != nil {
+		t.Fatalf("FindCacheByID failed: %v", err)
+	}
+	if stats := memory.Stats(); stats.Entries != 1 {
+		t.Errorf("Expected capacity to bound L1 to 1 entry, got %d", stats.Entries)
+	}
+
+	// 4. TTL이 지나면 L1을 건너뛰고 L2에서 다시 읽음
+	memory.TTL = time.Millisecond
+	memory.Purge()
+	memory.FindCacheByID(ctx, second)
+	time.Sleep(5 * time.Millisecond)
+	before := memory.Stats()
+	memory.FindCacheByID(ctx, second)
+	if after := memory.Stats(); after.Misses != before.Misses+1 {
+		t.Errorf("Expected expired entry to count as miss, got %+v -> %+v", before, after)
+	}
+}
+
+// pausingCacheRepo: FindCacheByID가 Next에서 읽은 뒤 read로 알리고, release가 닫힐 때까지 반환을 미룹니다.
+type pausingCacheRepo struct {
+	repository.CacheRepository
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (r *pausingCacheRepo) FindCacheByID(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
+	cached, err := r.CacheRepository.FindCacheByID(ctx, restaurantID)
+	if r.read != nil {
+		close(r.read)
+		r.read = nil
+		<-r.release
+	}
+	return cached, err
+}
+
+// TestMemoryCacheSkipsStaleFill: 미스로 L2를 읽는 사이 재계산(Upsert)이 끝나면, 먼저 읽은 이전 값을 L1에 채우지 않는지 확인합니다.
+func TestMemoryCacheSkipsStaleFill(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: L2에 평점 4로 계산된 식당, 첫 L2 조회는 읽은 뒤 멈춤
+	next := &pausingCacheRepo{CacheRepository: repository.NewCacheRepository(conn)}
+	memory := cache.NewMemoryCache(next, 0, time.Hour)
+	aggregator := cache.NewRatingAggregator(
+		repository.NewRestaurantRepository(conn),
+		repository.NewReviewRepository(conn),
+		memory,
+	)
+	id := createRestaurant(t, conn, [2]float64{4, 1})
+	if _, err := aggregator.Refresh(ctx, id); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	next.read, next.release = make(chan struct{}), make(chan struct{})
+	read := next.read
+
+	// 2. When: 미스 조회가 이전 값(4)을 읽은 상태에서 평점 5로 재계산한 뒤 조회를 마저 진행
+	done := make(chan *model.CacheMetadata)
+	go func() {
+		cached, _ := memory.FindCacheByID(ctx, id)
+		done <- cached
+	}()
+	<-read
+	if _, err := conn.Exec("UPDATE Review SET rating = 5 WHERE restaurant_ref_id = ?", id); err != nil {
+		t.Fatalf("Failed to update review: %v", err)
+	}
+	if _, err := aggregator.Refresh(ctx, id); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	close(next.release)
+	if stale := <-done; stale == nil || stale.WeightedRating != 4 {
+		t.Fatalf("Expected the in-flight read to return the value it read (4), got %+v", stale)
+	}
+
+	// 3. Then: 이전 값은 L1에 남지 않고, 다음 조회는 재계산된 값을 읽음
+	if stats := memory.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected the stale fill to be skipped, got %d entries", stats.Entries)
+	}
+	if cached, _ := memory.FindCacheByID(ctx, id); cached == nil || cached.WeightedRating != 5 {
+		t.Errorf("Expected refreshed value 5, got %+v", cached)
+	}
+}
@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/repository"
+)
+
+// PolicyConfig는 cache_score 기반 캐시 정책의 파라미터입니다.
+type PolicyConfig struct {
+	// MaxRows: Cache_Metadata에 유지할 최대 행 수 (0이면 제한 없음)
+	MaxRows int64
+	// HitScore: 조회 한 번마다 올라가는 cache_score
+	HitScore float64
+	// DecayFactor: Maintain 한 번마다 cache_score에 곱하는 감쇠 비율 (0 ~ 1)
+	DecayFactor float64
+	// EvictThreshold: 감쇠 후 이 점수 미만인 행은 캐시에서 내림
+	EvictThreshold float64
+	// PromoteThreshold: 캐시에 없는 식당이 이 횟수 이상 조회되면 hot으로 보고 캐시에 올림
+	PromoteThreshold int64
+	// InitialScore: Worker의 선제 갱신처럼 조회 없이 새로 만들어진 행의 cache_score (RatingAggregator.InitialScore로 연결)
+	// 감쇠 한 번 뒤에도 EvictThreshold 이상이어야 만들어진 직후의 Maintain에서 바로 내려가지 않음
+	InitialScore float64
+}
+
+// DefaultPolicyConfig: 기본 정책 (1000행, 조회당 +1, 주기마다 절반 감쇠, 새 행은 한 주기 동안 유지)
+func DefaultPolicyConfig() PolicyConfig {
+	return PolicyConfig{
+		MaxRows:          1000,
+		HitScore:         1,
+		DecayFactor:      0.5,
+		EvictThreshold:   0.5,
+		PromoteThreshold: 3,
+		InitialScore:     1,
+	}
+}
+
+// PolicyStats: Maintain 한 번의 결과
+type PolicyStats struct {
+	Evicted  int64
+	Promoted int64
+	Rows     int64
+}
+
+// Policy는 cache_score로 Cache_Metadata의 입장(admission)과 퇴출(eviction)을 결정합니다.
+// 조회마다 점수를 올리고, 주기적으로 감쇠시킨 뒤 낮은 점수의 행을 내리고 hot 식당을 올립니다.
+// 조회 기록(last_accessed_at, cache_score)은 메모리에 모았다가 Flush(Maintain 시작 시)에서 식당마다 한 번씩 씁니다.
+type Policy struct {
+	CacheRepo      repository.CacheRepository
+	RestaurantRepo repository.RestaurantRepository
+	Aggregator     *RatingAggregator
+	Config         PolicyConfig
+	Logger         *slog.Logger
+
+	mu sync.Mutex
+	// misses: 캐시에 없는 식당별 조회 횟수 (승격 후보)
+	misses map[int64]int64
+	// accessed: 마지막 Flush 이후 조회된 식당, hits: 그중 캐시 히트 횟수 (아직 DB에 쓰지 않음)
+	accessed map[int64]struct{}
+	hits     map[int64]int64
+}
+
+func NewPolicy(
+	cacheRepo repository.CacheRepository,
+	restaurantRepo repository.RestaurantRepository,
+	aggregator *RatingAggregator,
+	config PolicyConfig,
+	logger *slog.Logger,
+) *Policy {
+	return &Policy{
+		CacheRepo:      cacheRepo,
+		RestaurantRepo: restaurantRepo,
+		Aggregator:     aggregator,
+		Config:         config,
+		Logger:         logging.Component(logger, "cache_policy"),
+		misses:         make(map[int64]int64),
+		accessed:       make(map[int64]struct{}),
+		hits:           make(map[int64]int64),
+	}
+}
+
+// RecordAccess: 식당 조회를 메모리에 기록합니다. 캐시 히트면 cache_score에 더할 횟수를, 미스면 승격 후보 카운트를 올립니다.
+// (Restaurant.last_accessed_at과 cache_score는 다음 Flush에서 갱신)
+func (p *Policy) RecordAccess(restaurantID int64, hit bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.accessed[restaurantID] = struct{}{}
+	if hit {
+		p.hits[restaurantID]++
+	} else {
+		p.misses[restaurantID]++
+	}
+}
+
+// Flush: 마지막 Flush 이후 모은 조회 기록을 씁니다. 조회된 식당마다 last_accessed_at을 한 번 갱신하고,
+// 히트 횟수만큼 cache_score를 한 번에 올립니다. 쓰지 못한 기록은 다음 Flush에서 다시 씁니다.
+func (p *Policy) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	accessed, hits := p.accessed, p.hits
+	p.accessed, p.hits = make(map[int64]struct{}), make(map[int64]int64)
+	p.mu.Unlock()
+
+	for restaurantID := range accessed {
+		if err := p.RestaurantRepo.Touch(ctx, restaurantID); err != nil {
+			p.requeue(accessed, hits)
+			return err
+		}
+		delete(accessed, restaurantID)
+	}
+	for restaurantID, n := range hits {
+		if err := p.CacheRepo.IncrementScore(ctx, restaurantID, p.Config.HitScore*float64(n)); err != nil {
+			p.requeue(accessed, hits)
+			return err
+		}
+		delete(hits, restaurantID)
+	}
+	return nil
+}
+
+// requeue: Flush가 쓰지 못한 조회 기록을 그 사이 새로 모인 기록에 되돌려 놓습니다.
+func (p *Policy) requeue(accessed map[int64]struct{}, hits map[int64]int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for restaurantID := range accessed {
+		p.accessed[restaurantID] = struct{}{}
+	}
+	for restaurantID, n := range hits {
+		p.hits[restaurantID] += n
+	}
+}
+
+// Admit: 캐시 미스로 재구성한 식당을 캐시에 써 넣어도 되는지 결정합니다. (RecordAccess 이후에 호출)
+// 자리가 있으면 항상 허용하고, 가득 찼으면 hot 식당만 가장 낮은 점수의 행을 밀어내고 들어옵니다.
+func (p *Policy) Admit(ctx context.Context, restaurantID int64) (bool, error) {
+	if p.Config.MaxRows <= 0 {
+		return true, nil
+	}
+
+	rows, err := p.CacheRepo.Count(ctx)
+	if err != nil {
+		return false, err
+	}
+	if rows < p.Config.MaxRows {
+		return true, nil
+	}
+
+	p.mu.Lock()
+	hot := p.misses[restaurantID] >= p.Config.PromoteThreshold
+	p.mu.Unlock()
+	if !hot {
+		return false, nil
+	}
+
+	if _, err := p.CacheRepo.EvictLowest(ctx, rows-p.Config.MaxRows+1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Admitted: 캐시에 새로 들어온 식당의 점수에 누적된 조회 횟수만큼 더합니다. (Refresh가 준 초기 점수 위에 더함)
+func (p *Policy) Admitted(ctx context.Context, restaurantID int64) error {
+	p.mu.Lock()
+	accesses := p.misses[restaurantID]
+	delete(p.misses, restaurantID)
+	p.mu.Unlock()
+
+	if accesses < 1 {
+		accesses = 1
+	}
+	return p.CacheRepo.IncrementScore(ctx, restaurantID, p.Config.HitScore*float64(accesses))
+}
+
+// Maintain: 조회 기록 반영 -> 점수 감쇠 -> 임계값 미만 퇴출 -> hot 식당 승격 -> 최대 행 수 강제 순으로 한 주기를 실행합니다.
+func (p *Policy) Maintain(ctx context.Context) (PolicyStats, error) {
+	var stats PolicyStats
+
+	// 1. 모아 둔 조회 기록을 반영한 뒤 감쇠 및 임계값 미만 퇴출
+	if err := p.Flush(ctx); err != nil {
+		return stats, err
+	}
+	if err := p.CacheRepo.DecayScores(ctx, p.Config.DecayFactor); err != nil {
+		return stats, err
+	}
+	evicted, err := p.CacheRepo.EvictBelow(ctx, p.Config.EvictThreshold)
+	if err != nil {
+		return stats, err
+	}
+	stats.Evicted += evicted
+
+	// 2. 조회가 많은 순으로 hot 식당 승격
+	for _, restaurantID := range p.hotCandidates() {
+		if p.Config.MaxRows > 0 {
+			rows, err := p.CacheRepo.Count(ctx)
+			if err != nil {
+				return stats, err
+			}
+			if rows >= p.Config.MaxRows {
+				break
+			}
+		}
+
+		summary, err := p.Aggregator.Refresh(ctx, restaurantID)
+		if err != nil {
+			return stats, fmt.Errorf("failed to promote restaurant %d: %w", restaurantID, err)
+		}
+		if summary == nil {
+			p.forget(restaurantID) // 더 이상 존재하지 않는 식당
+			continue
+		}
+		if err := p.Admitted(ctx, restaurantID); err != nil {
+			return stats, err
+		}
+		stats.Promoted++
+	}
+
+	// 3. 최대 행 수 강제 (Worker의 선제 갱신 등으로 넘친 만큼 낮은 점수부터 퇴출)
+	rows, err := p.CacheRepo.Count(ctx)
+	if err != nil {
+		return stats, err
+	}
+	if p.Config.MaxRows > 0 && rows > p.Config.MaxRows {
+		evicted, err := p.CacheRepo.EvictLowest(ctx, rows-p.Config.MaxRows)
+		if err != nil {
+			return stats, err
+		}
+		stats.Evicted += evicted
+		rows -= evicted
+	}
+	stats.Rows = rows
+
+	// 4. 승격 후보 카운트도 같은 비율로 감쇠
+	p.decayMisses()
+
+	return stats, nil
+}
+
+// Run: interval마다 Maintain을 실행하는 메인 루프
+func (p *Policy) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			stats, err := p.Maintain(ctx)
+			if err != nil {
+				p.Logger.Error("failed to maintain cache policy", logging.Err(err))
+				continue
+			}
+			p.Logger.Info("cache policy maintained",
+				slog.Int64("evicted", stats.Evicted),
+				slog.Int64("promoted", stats.Promoted),
+				slog.Int64("rows", stats.Rows),
+				logging.Duration(time.Since(start)),
+			)
+		}
+	}
+}
+
+// hotCandidates: PromoteThreshold 이상 조회된 미캐시 식당을 조회 수 내림차순으로 반환합니다.
+func (p *Policy) hotCandidates() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var hot []int64
+	for restaurantID, accesses := range p.misses {
+		if accesses >= p.Config.PromoteThreshold {
+			hot = append(hot, restaurantID)
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		if p.misses[hot[i]] != p.misses[hot[j]] {
+			return p.misses[hot[i]] > p.misses[hot[j]]
+		}
+		return hot[i] < hot[j]
+	})
+	return hot
+}
+
+func (p *Policy) forget(restaurantID int64) {
+	p.mu.Lock()
+	delete(p.misses, restaurantID)
+	p.mu.Unlock()
+}
+
+func (p *Policy) decayMisses() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for restaurantID, accesses := range p.misses {
+		decayed := int64(float64(accesses) * p.Config.DecayFactor)
+		if decayed <= 0 {
+			delete(p.misses, restaurantID)
+			continue
+		}
+		p.misses[restaurantID] = decayed
+	}
+}
@@ -0,0 +1,136 @@
+package cache_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/repository"
+)
+
+func newPolicy(t *testing.T, config cache.PolicyConfig) (*cache.Policy, repository.CacheRepository, *sql.DB) {
+	conn := setupTestDB(t)
+	aggregator, cacheRepo := newAggregator(conn)
+	policy := cache.NewPolicy(cacheRepo, repository.NewRestaurantRepository(conn), aggregator, config, nil)
+	return policy, cacheRepo, conn
+}
+
+// TestPolicyScoresAndEvicts: 모아 둔 조회가 Flush에서 한 번에 점수를 올리고, 감쇠 후 임계값 미만인 행이 퇴출되는지 확인합니다.
+func TestPolicyScoresAndEvicts(t *testing.T) {
+	policy, cacheRepo, conn := newPolicy(t, cache.PolicyConfig{
+		MaxRows: 10, HitScore: 1, DecayFactor: 0.5, EvictThreshold: 1, PromoteThreshold: 3,
+	})
+	defer conn.Close()
+	ctx := context.Background()
+
+	hot := createRestaurant(t, conn, [2]float64{4, 1})
+	cold := createRestaurant(t, conn, [2]float64{3, 1})
+	for _, id := range []int64{hot, cold} {
+		if _, err := policy.Aggregator.Refresh(ctx, id); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	if _, err := conn.Exec("UPDATE Restaurant SET last_accessed_at = '2000-01-01 00:00:00'"); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// hot 식당만 4번 조회 -> 점수 4, cold는 0 (조회 기록은 Flush 전까지 DB에 쓰지 않음)
+	for i := 0; i < 4; i++ {
+		policy.RecordAccess(hot, true)
+	}
+
+	lastAccessed := func() string {
+		var at string
+		if err := conn.QueryRow("SELECT last_accessed_at FROM Restaurant WHERE restaurant_id = ?", hot).Scan(&at); err != nil {
+			t.Fatalf("Failed to read last_accessed_at: %v", err)
+		}
+		return at
+	}
+	if lastAccessed() != "2000-01-01 00:00:00" {
+		t.Errorf("Expected accesses to be batched until Flush")
+	}
+	if err := policy.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if lastAccessed() == "2000-01-01 00:00:00" {
+		t.Errorf("Expected last_accessed_at to be updated on flush")
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, hot); cached == nil || cached.CacheScore != 4 {
+		t.Errorf("Expected 4 hits to be written as one score update of 4, got %+v", cached)
+	}
+
+	stats, err := policy.Maintain(ctx)
+	if err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+
+	// 감쇠 후 hot = 2 (유지), cold = 0 (퇴출)
+	if stats.Evicted != 1 || stats.Rows != 1 {
+		t.Errorf("Expected 1 eviction leaving 1 row, got %+v", stats)
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, cold); cached != nil {
+		t.Errorf("Expected cold restaurant to be evicted")
+	}
+	cached, _ := cacheRepo.FindCacheByID(ctx, hot)
+	if cached == nil || cached.CacheScore != 2 {
+		t.Errorf("Expected hot restaurant with decayed score 2, got %+v", cached)
+	}
+}
+
+// TestPolicyAdmissionAndPromotion: 캐시가 가득 차면 hot 식당만 들어오고, 넘친 행은 낮은 점수부터 퇴출되는지 확인합니다.
+func TestPolicyAdmissionAndPromotion(t *testing.T) {
+	policy, cacheRepo, conn := newPolicy(t, cache.PolicyConfig{
+		MaxRows: 1, HitScore: 1, DecayFactor: 1, EvictThreshold: 0, PromoteThreshold: 2,
+	})
+	defer conn.Close()
+	ctx := context.Background()
+
+	resident := createRestaurant(t, conn, [2]float64{4, 1})
+	newcomer := createRestaurant(t, conn, [2]float64{2, 1})
+	if _, err := policy.Aggregator.Refresh(ctx, resident); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// 1. 첫 미스: 캐시가 가득 찼고 아직 hot이 아니므로 거절
+	policy.RecordAccess(newcomer, false)
+	admit, err := policy.Admit(ctx, newcomer)
+	if err != nil {
+		t.Fatalf("Admit failed: %v", err)
+	}
+	if admit {
+		t.Errorf("Expected cold newcomer to be refused while cache is full")
+	}
+
+	// 2. 두 번째 미스: hot이 되었으므로 가장 낮은 점수의 행을 밀어내고 입장
+	policy.RecordAccess(newcomer, false)
+	admit, err = policy.Admit(ctx, newcomer)
+	if err != nil {
+		t.Fatalf("Admit failed: %v", err)
+	}
+	if !admit {
+		t.Fatalf("Expected hot newcomer to be admitted")
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, resident); cached != nil {
+		t.Errorf("Expected resident with lowest score to be evicted")
+	}
+
+	// 3. Maintain이 미캐시 hot 식당을 자리가 생기면 승격
+	if err := cacheRepo.Delete(ctx, newcomer); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		policy.RecordAccess(resident, false)
+	}
+	stats, err := policy.Maintain(ctx)
+	if err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if stats.Promoted != 1 || stats.Rows != 1 {
+		t.Errorf("Expected resident to be promoted back, got %+v", stats)
+	}
+	cached, _ := cacheRepo.FindCacheByID(ctx, resident)
+	if cached == nil || cached.CacheScore != 2 {
+		t.Errorf("Expected promoted row with score 2, got %+v", cached)
+	}
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3" // DB 드라이버
+)
+
+//go:embed schema.sql
+var schemaSQL string
+
+// migrationFS: 2번 이후의 마이그레이션 SQL 파일 (migrations/NNN_이름.sql)
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// MemoryDSN: 프로세스 안에서 공유되는 기본 인메모리 DB
+const MemoryDSN = "file::memory:?cache=shared"
+
+// Migration은 schema_version 테이블로 추적되는 번호 붙은 스키마 변경 단위입니다.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrations: 버전 순으로 정렬된 전체 마이그레이션 목록 (1번은 최초 schema.sql)
+var migrations = []Migration{
+	{Version: 1, Name: "initial schema", SQL: schemaSQL},
+	{Version: 2, Name: "buffer retry and dead letter", SQL: migrationSQL("002_buffer_retry.sql")},
+	{Version: 3, Name: "buffer claim lease", SQL: migrationSQL("003_buffer_lease.sql")},
+	{Version: 4, Name: "buffer ordering indexes", SQL: migrationSQL("004_buffer_ordering.sql")},
+	{Version: 5, Name: "buffer payload version", SQL: migrationSQL("005_payload_version.sql")},
+	{Version: 6, Name: "buffer idempotency keys", SQL: migrationSQL("006_idempotency_key.sql")},
+	{Version: 7, Name: "buffer log autoincrement", SQL: migrationSQL("007_buffer_log_autoincrement.sql")},
+}
+
+// migrationSQL: 내장된 마이그레이션 파일을 읽습니다. (빌드에 포함되므로 없으면 프로그래밍 오류)
+func migrationSQL(name string) string {
+	data, err := migrationFS.ReadFile("migrations/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("missing embedded migration %s: %v", name, err))
+	}
+	return string(data)
+}
+
+// Migrations: 등록된 마이그레이션 목록의 복사본을 반환합니다.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestVersion: 가장 최신 스키마 버전
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// MemoryDSNFor: name별로 분리된 인메모리 DB의 DSN을 만듭니다. (테스트 간 격리용)
+func MemoryDSNFor(name string) string {
+	name = strings.NewReplacer("/", "_", " ", "_", "?", "_", "&", "_").Replace(name)
+	return fmt.Sprintf("file:%s?mode=memory&cache=shared", name)
+}
+
+// InitDB: DSN으로 DB를 열고 최신 버전까지 마이그레이션을 적용합니다.
+// dsn이 비어 있으면 MemoryDSN을 사용하고, 파일 경로라면 디스크 DB를 열거나 새로 만듭니다.
+func InitDB(dsn string) (*sql.DB, error) {
+	conn, err := Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(context.Background(), conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Open: 마이그레이션 없이 DB 연결만 엽니다.
+func Open(dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		dsn = MemoryDSN
+	}
+	if !strings.Contains(dsn, "mode=memory") && !strings.Contains(dsn, ":memory:") && !strings.Contains(dsn, "?") {
+		// 디스크 DB: 동시 접근 시 SQLITE_BUSY를 줄이기 위해 WAL과 busy timeout을 켭니다.
+		// 트랜잭션은 시작할 때 바로 쓰기 잠금을 잡아(IMMEDIATE) 여러 Worker가 읽기 -> 쓰기로 올리다 충돌하지 않게 합니다.
+		dsn += "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+	}
+
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database connection: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	return conn, nil
+}
+
+// Migrate: 아직 적용되지 않은 마이그레이션을 버전 순으로 하나씩 트랜잭션 안에서 적용합니다.
+// schema_version 테이블이 없는 기존 DB 파일(schema.sql만 실행된 상태)은 1번 버전으로 간주하고 그 이후부터 적용합니다.
+func Migrate(ctx context.Context, conn *sql.DB) error {
+	return migrate(ctx, conn, migrations)
+}
+
+func migrate(ctx context.Context, conn *sql.DB, list []Migration) error {
+	current, err := SchemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range list {
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion: 현재 DB에 적용된 스키마 버전을 조회합니다. (빈 DB는 0)
+func SchemaVersion(ctx context.Context, conn *sql.DB) (int, error) {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now'))
+		)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	var version int
+	err = conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > 0 {
+		return version, nil
+	}
+
+	// schema_version 도입 이전에 만들어진 DB인지 확인 (Buffer_Log가 이미 있으면 1번 스키마가 적용된 상태)
+	var legacy int
+	err = conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'Buffer_Log'`,
+	).Scan(&legacy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect existing schema: %w", err)
+	}
+	if legacy == 0 {
+		return 0, nil
+	}
+
+	_, err = conn.ExecContext(ctx,
+		`INSERT INTO schema_version (version, name) VALUES (?, ?)`,
+		migrations[0].Version, migrations[0].Name,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record baseline schema version: %w", err)
+	}
+	return migrations[0].Version, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.DB, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_version (version, name) VALUES (?, ?)`, m.Version, m.Name,
+	); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return nil
+}
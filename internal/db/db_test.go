@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// TestInitDBInMemory: 빈 인메모리 DB가 최신 버전까지 마이그레이션되는지 확인합니다.
+func TestInitDBInMemory(t *testing.T) {
+	conn, err := InitDB(MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer conn.Close()
+
+	version, err := SchemaVersion(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestVersion() {
+		t.Errorf("Expected schema version %d, got %d", LatestVersion(), version)
+	}
+
+	var count int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Buffer_Log").Scan(&count); err != nil {
+		t.Fatalf("Expected Buffer_Log table to exist: %v", err)
+	}
+}
+
+// TestMigrateUpgradesLegacyFile: schema.sql만 실행된 기존 DB 파일을 데이터 손실 없이 업그레이드하는지 확인합니다.
+func TestMigrateUpgradesLegacyFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	// 1. Given: schema_version 없이 schema.sql로만 만들어진 DB 파일과 기존 데이터
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("could not open legacy db: %v", err)
+	}
+	if _, err := legacy.Exec(schemaSQL); err != nil {
+		t.Fatalf("could not execute schema: %v", err)
+	}
+	if _, err := legacy.Exec(`INSERT INTO User (username) VALUES ('legacy-user')`); err != nil {
+		t.Fatalf("could not insert legacy user: %v", err)
+	}
+	legacy.Close()
+
+	// 2. When: 같은 파일을 새 마이그레이션 목록으로 다시 연다
+	conn, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer conn.Close()
+
+	upgraded := append(Migrations(), Migration{
+		Version: LatestVersion() + 1,
+		Name:    "test column",
+		SQL:     `ALTER TABLE User ADD COLUMN nickname TEXT NOT NULL DEFAULT ''`,
+	})
+	if err := migrate(ctx, conn, upgraded); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	// 두 번 실행해도 이미 적용된 버전은 건너뛰어야 함
+	if err := migrate(ctx, conn, upgraded); err != nil {
+		t.Fatalf("second migrate failed: %v", err)
+	}
+
+	// 3. Then: 버전이 올라가고 기존 데이터와 새 컬럼이 모두 존재
+	version, err := SchemaVersion(ctx, conn)
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestVersion()+1 {
+		t.Errorf("Expected schema version %d, got %d", LatestVersion()+1, version)
+	}
+
+	var username, nickname string
+	err = conn.QueryRow(`SELECT username, nickname FROM User WHERE username = 'legacy-user'`).Scan(&username, &nickname)
+	if err != nil {
+		t.Fatalf("Expected legacy user to survive migration: %v", err)
+	}
+}
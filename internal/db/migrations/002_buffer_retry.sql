@@ -0,0 +1,21 @@
+-- 002: Buffer_Log 재시도 추적 및 Dead Letter 테이블
+
+-- 반영 실패 횟수, 마지막 오류, 다음 재시도 가능 시각 (NULL이면 바로 재시도 가능)
+ALTER TABLE Buffer_Log ADD COLUMN attempt_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Buffer_Log ADD COLUMN last_error TEXT;
+ALTER TABLE Buffer_Log ADD COLUMN next_retry_at TEXT;
+
+-- Buffer_Dead_Letter: 최대 재시도 횟수를 넘긴 로그를 옮겨 두는 테이블 (운영자가 확인/수정 후 재투입)
+CREATE TABLE Buffer_Dead_Letter(
+    dead_letter_id INTEGER PRIMARY KEY,
+    original_log_id INTEGER NOT NULL, -- 옮겨지기 전 Buffer_Log의 log_id
+
+    transaction_type TEXT NOT NULL,
+    target_table TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    target_record_id INTEGER,
+
+    attempt_count INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    failed_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now'))
+);
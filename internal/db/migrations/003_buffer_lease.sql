@@ -0,0 +1,5 @@
+-- 003: 여러 CheckpointWorker가 Buffer_Log를 나눠 처리하기 위한 claim/lease 컬럼
+
+-- claimed_by: 로그를 가져간 Worker ID, lease_expires_at: 이 시각이 지나면 다른 Worker가 다시 가져갈 수 있음
+ALTER TABLE Buffer_Log ADD COLUMN claimed_by TEXT;
+ALTER TABLE Buffer_Log ADD COLUMN lease_expires_at TEXT;
@@ -0,0 +1,8 @@
+-- 004: Buffer_Log 순서 보장을 위한 인덱스
+-- log_updated_at은 초 단위라 같은 초에 들어온 로그의 순서를 구분하지 못하므로 log_id 기준으로 바꿉니다.
+
+DROP INDEX IF EXISTS idx_buffer_pending;
+CREATE INDEX idx_buffer_pending ON Buffer_Log (is_committed, log_id);
+
+-- 같은 레코드에 대해 앞선 미반영 로그가 있는지 ClaimBatch가 확인할 때 사용 (미반영 로그만 색인)
+CREATE INDEX idx_buffer_record ON Buffer_Log (target_table, target_record_id, log_id) WHERE is_committed = 0;
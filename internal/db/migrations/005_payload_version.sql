@@ -0,0 +1,5 @@
+-- 005: payload 버전 (Worker가 예전 형태의 payload를 현재 형태로 올려서 해석하는 데 사용)
+
+-- 버전이 도입되기 전에 쌓인 로그와 Dead Letter는 버전 1
+ALTER TABLE Buffer_Log ADD COLUMN payload_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE Buffer_Dead_Letter ADD COLUMN payload_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+-- 006: AddLog 멱등성 키 (재시도한 같은 요청이 로그를 두 번 쌓지 않도록 함)
+
+-- 키는 로그와 별도로 보관 기간(expires_at)까지 남음 (반영된 로그가 지워진 뒤에도 중복 요청을 막기 위함)
+CREATE TABLE Buffer_Idempotency_Key(
+    idempotency_key TEXT PRIMARY KEY,
+    log_id INTEGER NOT NULL, -- 이 키로 처음 추가된 Buffer_Log의 log_id
+
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now')),
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_idempotency_expires ON Buffer_Idempotency_Key (expires_at);
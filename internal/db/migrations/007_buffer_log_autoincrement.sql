@@ -0,0 +1,46 @@
+-- 007: Buffer_Log의 log_id를 AUTOINCREMENT로 바꿔 지워진 로그의 ID가 다시 쓰이지 않게 합니다.
+-- 마지막 로그가 Dead Letter로 옮겨지거나 압축으로 지워지면 같은 ID가 새 로그에 다시 할당되어,
+-- log_id로 앞뒤를 비교하는 곳(Dead Letter 재투입, 멱등성 키)이 새 로그를 예전 로그로 착각할 수 있습니다.
+
+CREATE TABLE Buffer_Log_New(
+    log_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_type TEXT NOT NULL,
+    target_table TEXT NOT NULL,
+
+    payload TEXT NOT NULL,
+    target_record_id INTEGER,
+
+    log_updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now')),
+    is_committed INTEGER NOT NULL DEFAULT 0,
+
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_retry_at TEXT,
+    claimed_by TEXT,
+    lease_expires_at TEXT,
+    payload_version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO Buffer_Log_New (
+    log_id, transaction_type, target_table, payload, target_record_id, log_updated_at, is_committed,
+    attempt_count, last_error, next_retry_at, claimed_by, lease_expires_at, payload_version
+)
+SELECT
+    log_id, transaction_type, target_table, payload, target_record_id, log_updated_at, is_committed,
+    attempt_count, last_error, next_retry_at, claimed_by, lease_expires_at, payload_version
+FROM Buffer_Log;
+
+DROP TABLE Buffer_Log;
+ALTER TABLE Buffer_Log_New RENAME TO Buffer_Log;
+
+CREATE INDEX idx_buffer_pending ON Buffer_Log (is_committed, log_id);
+CREATE INDEX idx_buffer_record ON Buffer_Log (target_table, target_record_id, log_id) WHERE is_committed = 0;
+
+-- 이미 지워진 로그의 ID(Dead Letter, 멱등성 키에 남은 ID)보다 큰 값부터 할당
+DELETE FROM sqlite_sequence WHERE name = 'Buffer_Log';
+INSERT INTO sqlite_sequence (name, seq)
+SELECT 'Buffer_Log', MAX(
+    COALESCE((SELECT MAX(log_id) FROM Buffer_Log), 0),
+    COALESCE((SELECT MAX(original_log_id) FROM Buffer_Dead_Letter), 0),
+    COALESCE((SELECT MAX(log_id) FROM Buffer_Idempotency_Key), 0)
+);
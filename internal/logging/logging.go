@@ -0,0 +1,86 @@
+// Package logging은 서비스, Worker, Repository가 함께 쓰는 log/slog 로거 설정과 공통 필드 이름을 제공합니다.
+// 각 구성 요소는 생성자로 *slog.Logger를 받고(nil이면 slog.Default), component 필드를 붙여 사용합니다.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// 출력 형식
+const (
+	FormatText = "text" // key=value 한 줄
+	FormatJSON = "json" // 한 줄에 JSON 객체 하나 (로그 파이프라인 전송용)
+)
+
+// 공통 필드 이름 (같은 값은 어느 구성 요소에서든 같은 이름으로 기록)
+const (
+	KeyComponent    = "component"
+	KeyRestaurantID = "restaurant_id"
+	KeyUserID       = "user_id"
+	KeyLogID        = "log_id"
+	KeyBatchSize    = "batch_size"
+	KeyDuration     = "duration"
+	KeyWorkerID     = "worker_id"
+	KeyError        = "error"
+)
+
+// Config: 로거 설정
+type Config struct {
+	Format string     // FormatText 또는 FormatJSON (비어 있으면 text)
+	Level  slog.Level // 이보다 낮은 레벨은 버림
+	Output io.Writer
+}
+
+// New: Config에 맞는 로거를 만듭니다. 알 수 없는 형식이면 오류를 반환합니다.
+func New(config Config) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: config.Level}
+
+	switch strings.ToLower(config.Format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(config.Output, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(config.Output, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want %s or %s)", config.Format, FormatText, FormatJSON)
+	}
+}
+
+// ParseLevel: "debug", "info", "warn", "error"를 slog.Level로 바꿉니다.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q: %w", s, err)
+	}
+	return level, nil
+}
+
+// Component: logger(nil이면 slog.Default)에 component 필드를 붙입니다. 생성자에서 사용합니다.
+func Component(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(KeyComponent, name)
+}
+
+// Discard: 아무것도 출력하지 않는 로거 (테스트용)
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+func RestaurantID(id int64) slog.Attr { return slog.Int64(KeyRestaurantID, id) }
+
+func UserID(id int64) slog.Attr { return slog.Int64(KeyUserID, id) }
+
+func LogID(id int64) slog.Attr { return slog.Int64(KeyLogID, id) }
+
+func BatchSize(n int) slog.Attr { return slog.Int(KeyBatchSize, n) }
+
+func Duration(d time.Duration) slog.Attr { return slog.Duration(KeyDuration, d) }
+
+func WorkerID(id string) slog.Attr { return slog.String(KeyWorkerID, id) }
+
+func Err(err error) slog.Attr { return slog.Any(KeyError, err) }
@@ -0,0 +1,49 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/logging"
+)
+
+func TestJSONLoggerUsesCommonFields(t *testing.T) {
+	// 1. Given: info 레벨 JSON 로거
+	var out bytes.Buffer
+	logger, err := logging.New(logging.Config{Format: logging.FormatJSON, Level: slog.LevelInfo, Output: &out})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger = logging.Component(logger, "checkpoint_worker")
+
+	// 2. When: debug 하나와 info 하나를 기록
+	logger.Debug("dropped")
+	logger.Info("batch committed", logging.RestaurantID(3), logging.LogID(42), logging.BatchSize(100), logging.Duration(time.Millisecond))
+
+	// 3. Then: info만 한 줄의 JSON으로 남고, 공통 필드 이름을 사용
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line above debug level, got %q", out.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", lines[0], err)
+	}
+	want := map[string]any{
+		"msg": "batch committed", "component": "checkpoint_worker",
+		"restaurant_id": 3.0, "log_id": 42.0, "batch_size": 100.0, "duration": float64(time.Millisecond),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+
+	if _, err := logging.New(logging.Config{Format: "xml", Output: &out}); err == nil {
+		t.Fatal("Expected an unknown format to be rejected")
+	}
+}
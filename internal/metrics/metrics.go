@@ -0,0 +1,143 @@
+// Package metrics는 캐시와 버퍼 상태를 Prometheus 텍스트 형식으로 내보냅니다.
+// 서비스와 Worker는 *Metrics 필드(nil이면 기록하지 않음)로, Repository는 이 패키지의 데코레이터로 계측합니다.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/repository"
+)
+
+// DefaultScrapeTimeout: 한 번의 /metrics 요청에서 DB를 읽는 게이지 계산에 쓸 수 있는 시간
+const DefaultScrapeTimeout = 2 * time.Second
+
+// 캐시 조회 결과 (restaurant_db_cache_requests_total의 result 레이블)
+const (
+	CacheHit   = "hit"
+	CacheStale = "stale" // 캐시에 있었지만 허용 지연을 넘겨 다시 계산
+	CacheMiss  = "miss"
+)
+
+// Metrics는 이 서비스가 내보내는 지표 묶음입니다.
+type Metrics struct {
+	Registry *Registry
+
+	// Operations: 구성 요소(component)의 연산(operation)별 지연 시간 (Repository 메서드, 서비스 조회, 체크포인트)
+	Operations *HistogramVec
+	// OperationErrors: 오류로 끝난 연산 수
+	OperationErrors *CounterVec
+	// CacheRequests: FindRestaurantSummary의 캐시 조회 결과별 수
+	CacheRequests *CounterVec
+	// CheckpointLogs: ProcessCheckpoint가 처리한 로그의 결과(committed, retried, dead_lettered, rejected, deferred, rolled_back)별 수 (결과끼리 겹치지 않음)
+	CheckpointLogs *CounterVec
+	// CheckpointBatchSize: ProcessCheckpoint 한 번에 가져온 로그 수
+	CheckpointBatchSize *HistogramVec
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry: r,
+		Operations: r.NewHistogramVec("restaurant_db_operation_duration_seconds",
+			"Latency of service, worker and repository operations.", DefaultLatencyBuckets, "component", "operation"),
+		OperationErrors: r.NewCounterVec("restaurant_db_operation_errors_total",
+			"Operations that returned an error.", "component", "operation"),
+		CacheRequests: r.NewCounterVec("restaurant_db_cache_requests_total",
+			"Restaurant summary reads by cache result (hit, stale, miss).", "result"),
+		CheckpointLogs: r.NewCounterVec("restaurant_db_checkpoint_logs_total",
+			"Buffer logs handled by the checkpoint worker by outcome.", "outcome"),
+		CheckpointBatchSize: r.NewHistogramVec("restaurant_db_checkpoint_batch_size",
+			"Buffer logs claimed per checkpoint.", []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}),
+	}
+}
+
+// Observe: start부터 지금까지를 component/operation의 지연 시간으로 기록하고, err가 있으면 오류 수를 올립니다.
+func (m *Metrics) Observe(component, operation string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	m.Operations.ObserveDuration(time.Since(start), component, operation)
+	if err != nil {
+		m.OperationErrors.Inc(component, operation)
+	}
+}
+
+// CacheRequest: 캐시 조회 결과(CacheHit, CacheStale, CacheMiss)를 하나 기록합니다.
+func (m *Metrics) CacheRequest(result string) {
+	if m == nil {
+		return
+	}
+	m.CacheRequests.Inc(result)
+}
+
+// CheckpointBatch: 체크포인트 한 번에 가져온 로그 수와 결과별 로그 수를 기록합니다.
+func (m *Metrics) CheckpointBatch(fetched int, outcomes map[string]int) {
+	if m == nil {
+		return
+	}
+	m.CheckpointBatchSize.Observe(float64(fetched))
+	for outcome, n := range outcomes {
+		m.CheckpointLogs.Add(float64(n), outcome)
+	}
+}
+
+// RegisterBufferGauges: 내보낼 때마다 DB에서 읽는 버퍼 상태 게이지를 등록합니다.
+// restaurant_db_buffer_lag_seconds는 가장 오래된 반영 대기 로그가 추가된 뒤 지난 시간이며, 대기 로그가 없으면 0입니다.
+func (m *Metrics) RegisterBufferGauges(bufferRepo repository.BufferRepository, deadLetterRepo repository.DeadLetterRepository) {
+	m.Registry.NewGaugeFunc("restaurant_db_buffer_pending_logs",
+		"Buffer logs not yet applied to the relations.",
+		func(ctx context.Context) (float64, error) {
+			n, err := bufferRepo.CountPending(ctx)
+			return float64(n), err
+		})
+	m.Registry.NewGaugeFunc("restaurant_db_buffer_lag_seconds",
+		"Age of the oldest buffer log not yet applied (0 when the buffer is empty).",
+		func(ctx context.Context) (float64, error) {
+			oldest, err := bufferRepo.OldestPendingAt(ctx)
+			if err != nil || oldest == nil {
+				return 0, err
+			}
+			return time.Since(*oldest).Seconds(), nil
+		})
+	if deadLetterRepo != nil {
+		m.Registry.NewGaugeFunc("restaurant_db_dead_letter_logs",
+			"Buffer logs waiting in the dead letter table.",
+			func(ctx context.Context) (float64, error) {
+				n, err := deadLetterRepo.Count(ctx)
+				return float64(n), err
+			})
+	}
+}
+
+// Serve: addr에서 /metrics를 제공하는 HTTP 서버를 띄우고, ctx가 끝나면 닫습니다.
+// 포트를 열지 못하면 바로 오류를 반환하고, 그 뒤의 오류는 logger로 기록합니다.
+func (m *Metrics) Serve(ctx context.Context, addr string, logger *slog.Logger) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Registry.Handler(DefaultScrapeTimeout))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	logger = logging.Component(logger, "metrics")
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", logging.Err(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	logger.Info("metrics server listening", slog.String("addr", listener.Addr().String()))
+	return listener.Addr(), nil
+}
@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/metrics"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+func TestMetricsEndpointReportsBufferLag(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: 계측된 BufferRepository로 추가한 로그 하나가 1분 전부터 반영을 기다리는 중
+	m := metrics.New()
+	bufferRepo := metrics.NewBufferRepository(repository.NewBufferRepository(conn), m)
+	m.RegisterBufferGauges(bufferRepo, repository.NewDeadLetterRepository(conn))
+
+	log := model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", TargetRecordID: 1,
+		Payload: `{"mode": "set", "user_id": 1, "new_score": 0.5, "new_review_count": 1, "new_bias_count": 0}`}
+	if err := bufferRepo.AddLog(ctx, &log); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+	backdated := time.Now().Add(-time.Minute).UTC().Format("2006-01-02 15:04:05")
+	if _, err := conn.Exec(`UPDATE Buffer_Log SET log_updated_at = ? WHERE log_id = ?`, backdated, log.LogID); err != nil {
+		t.Fatalf("could not backdate log: %v", err)
+	}
+	m.CacheRequest(metrics.CacheHit)
+
+	// 2. When: /metrics 요청
+	recorder := httptest.NewRecorder()
+	m.Registry.Handler(time.Second).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	text := string(body)
+
+	// 3. Then: Prometheus 텍스트 형식으로 카운터, 히스토그램, 버퍼 게이지를 내보냄
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", ct)
+	}
+	for _, want := range []string{
+		"# TYPE restaurant_db_operation_duration_seconds histogram\n",
+		`restaurant_db_operation_duration_seconds_bucket{component="buffer_repository",operation="add_log",le="+Inf"} 1` + "\n",
+		`restaurant_db_operation_duration_seconds_count{component="buffer_repository",operation="add_log"} 1` + "\n",
+		`restaurant_db_cache_requests_total{result="hit"} 1` + "\n",
+		"restaurant_db_buffer_pending_logs 1\n",
+		"restaurant_db_dead_letter_logs 0\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	lag := -1.0
+	for _, line := range strings.Split(text, "\n") {
+		if value, ok := strings.CutPrefix(line, "restaurant_db_buffer_lag_seconds "); ok {
+			lag, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	if lag < 59 || lag > 120 {
+		t.Errorf("Expected buffer lag of about 60 seconds, got %v", lag)
+	}
+}
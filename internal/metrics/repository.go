@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// Repository 데코레이터: 읽기/쓰기 경로에서 자주 불리는 메서드의 지연 시간과 오류를 기록하고, 나머지는 그대로 넘깁니다.
+// (CheckpointWorker가 트랜잭션 안에서 만드는 Repository는 계측하지 않고, 배치 전체를 process_checkpoint로 기록)
+
+// BufferRepository: repository.BufferRepository 계측 (component="buffer_repository")
+type BufferRepository struct {
+	repository.BufferRepository
+	Metrics *Metrics
+}
+
+func NewBufferRepository(next repository.BufferRepository, m *Metrics) repository.BufferRepository {
+	return &BufferRepository{BufferRepository: next, Metrics: m}
+}
+
+func (r *BufferRepository) AddLog(ctx context.Context, log *model.BufferLog) (err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "add_log", start, err) }(time.Now())
+	return r.BufferRepository.AddLog(ctx, log)
+}
+
+func (r *BufferRepository) GetPendingLogs(ctx context.Context, limit int) (logs []model.BufferLog, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "get_pending_logs", start, err) }(time.Now())
+	return r.BufferRepository.GetPendingLogs(ctx, limit)
+}
+
+func (r *BufferRepository) ClaimBatch(ctx context.Context, workerID string, lease time.Duration, limit int) (logs []model.BufferLog, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "claim_batch", start, err) }(time.Now())
+	return r.BufferRepository.ClaimBatch(ctx, workerID, lease, limit)
+}
+
+func (r *BufferRepository) ReleaseClaims(ctx context.Context, workerID string, logIDs []int64) (err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "release_claims", start, err) }(time.Now())
+	return r.BufferRepository.ReleaseClaims(ctx, workerID, logIDs)
+}
+
+func (r *BufferRepository) CountPending(ctx context.Context) (n int64, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "count_pending", start, err) }(time.Now())
+	return r.BufferRepository.CountPending(ctx)
+}
+
+func (r *BufferRepository) GetPendingForRecord(ctx context.Context, table string, recordID int64) (logs []model.BufferLog, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("buffer_repository", "get_pending_for_record", start, err) }(time.Now())
+	return r.BufferRepository.GetPendingForRecord(ctx, table, recordID)
+}
+
+func (r *BufferRepository) CountPendingForRestaurant(ctx context.Context, restaurantID int64) (n int64, err error) {
+	defer func(start time.Time) {
+		r.Metrics.Observe("buffer_repository", "count_pending_for_restaurant", start, err)
+	}(time.Now())
+	return r.BufferRepository.CountPendingForRestaurant(ctx, restaurantID)
+}
+
+// CacheRepository: Cache_Metadata(L2) 계측 (component="cache_repository")
+type CacheRepository struct {
+	repository.CacheRepository
+	Metrics *Metrics
+}
+
+func NewCacheRepository(next repository.CacheRepository, m *Metrics) repository.CacheRepository {
+	return &CacheRepository{CacheRepository: next, Metrics: m}
+}
+
+func (r *CacheRepository) FindCacheByID(ctx context.Context, restaurantID int64) (cache *model.CacheMetadata, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("cache_repository", "find_cache_by_id", start, err) }(time.Now())
+	return r.CacheRepository.FindCacheByID(ctx, restaurantID)
+}
+
+func (r *CacheRepository) Upsert(ctx context.Context, cache *model.CacheMetadata) (err error) {
+	defer func(start time.Time) { r.Metrics.Observe("cache_repository", "upsert", start, err) }(time.Now())
+	return r.CacheRepository.Upsert(ctx, cache)
+}
+
+// RestaurantRepository: Restaurant 계측 (component="restaurant_repository")
+type RestaurantRepository struct {
+	repository.RestaurantRepository
+	Metrics *Metrics
+}
+
+func NewRestaurantRepository(next repository.RestaurantRepository, m *Metrics) repository.RestaurantRepository {
+	return &RestaurantRepository{RestaurantRepository: next, Metrics: m}
+}
+
+func (r *RestaurantRepository) FindByID(ctx context.Context, restaurantID int64) (restaurant *model.Restaurant, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("restaurant_repository", "find_by_id", start, err) }(time.Now())
+	return r.RestaurantRepository.FindByID(ctx, restaurantID)
+}
+
+func (r *RestaurantRepository) Create(ctx context.Context, restaurant *model.Restaurant) (err error) {
+	defer func(start time.Time) { r.Metrics.Observe("restaurant_repository", "create", start, err) }(time.Now())
+	return r.RestaurantRepository.Create(ctx, restaurant)
+}
+
+// ReviewRepository: Review 계측 (component="review_repository")
+type ReviewRepository struct {
+	repository.ReviewRepository
+	Metrics *Metrics
+}
+
+func NewReviewRepository(next repository.ReviewRepository, m *Metrics) repository.ReviewRepository {
+	return &ReviewRepository{ReviewRepository: next, Metrics: m}
+}
+
+func (r *ReviewRepository) WeightedRating(ctx context.Context, restaurantID int64) (rating float64, count int64, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("review_repository", "weighted_rating", start, err) }(time.Now())
+	return r.ReviewRepository.WeightedRating(ctx, restaurantID)
+}
+
+func (r *ReviewRepository) ConsensusDeviation(ctx context.Context, userID int64) (deviation float64, count int64, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("review_repository", "consensus_deviation", start, err) }(time.Now())
+	return r.ReviewRepository.ConsensusDeviation(ctx, userID)
+}
+
+// UserRepository: User 계측 (component="user_repository")
+type UserRepository struct {
+	repository.UserRepository
+	Metrics *Metrics
+}
+
+func NewUserRepository(next repository.UserRepository, m *Metrics) repository.UserRepository {
+	return &UserRepository{UserRepository: next, Metrics: m}
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, userID int64) (user *model.User, err error) {
+	defer func(start time.Time) { r.Metrics.Observe("user_repository", "find_by_id", start, err) }(time.Now())
+	return r.UserRepository.FindByID(ctx, userID)
+}
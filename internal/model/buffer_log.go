@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+// Buffer_Log.transaction_type 값
+const (
+	TransactionInsert = "INSERT"
+	TransactionUpdate = "UPDATE"
+	TransactionDelete = "DELETE"
+)
+
 type BufferLog struct {
 	// log_id INTEGER PRIMARY KEY
 	LogID int64 `db:"log_id"`
@@ -17,6 +24,9 @@ type BufferLog struct {
 	// payload TEXT NOT NULL -- 페이로드는 json (Go에서는 string으로 처리)
 	Payload string `db:"payload"`
 
+	// payload_version INTEGER NOT NULL DEFAULT 1 -- payload 형태의 버전 (0이면 정해지지 않음: 검증기가 현재 버전으로 채우고, 검증 없이 저장된 0은 현재 버전으로 해석)
+	PayloadVersion int `db:"payload_version"`
+
 	// target_record_id INTEGER -- 실제 transaction_type에 따라 적용시킬 레코드의 id
 	TargetRecordID int64 `db:"target_record_id"` // NULL 허용되지만, 구조체에서는 int64 포인터 대신 0으로 처리하거나 sql.NullInt64 사용 가능. 일단 단순화하여 int64로 정의
 
@@ -25,4 +35,22 @@ type BufferLog struct {
 
 	// is_committed INTEGER NOT NULL DEFAULT 0 -- splite에서는 boolean을 못쓴다네요..?
 	IsCommitted int64 `db:"is_committed"` // SQLite의 INTEGER(0 또는 1)에 맞춰 int64로 정의
+
+	// attempt_count INTEGER NOT NULL DEFAULT 0 -- 반영 실패 횟수
+	AttemptCount int64 `db:"attempt_count"`
+
+	// last_error TEXT -- 마지막 반영 실패 사유 (실패한 적 없으면 빈 문자열)
+	LastError string `db:"last_error"`
+
+	// next_retry_at TEXT -- 다음 재시도 가능 시각 (zero value면 바로 재시도 가능)
+	NextRetryAt time.Time `db:"next_retry_at"`
+
+	// claimed_by TEXT -- 로그를 가져가 처리 중인 Worker ID (없으면 빈 문자열)
+	ClaimedBy string `db:"claimed_by"`
+
+	// lease_expires_at TEXT -- claim이 만료되는 시각 (지나면 다른 Worker가 다시 가져갈 수 있음)
+	LeaseExpiresAt time.Time `db:"lease_expires_at"`
+
+	// Buffer_Idempotency_Key.idempotency_key -- 같은 요청의 재시도를 구분하는 키 (비어 있으면 중복 검사 안 함)
+	IdempotencyKey string `db:"idempotency_key"`
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// DeadLetter는 최대 재시도 횟수를 넘겨 Buffer_Log에서 Buffer_Dead_Letter로 옮겨진 로그입니다.
+type DeadLetter struct {
+	DeadLetterID  int64 `db:"dead_letter_id"`
+	OriginalLogID int64 `db:"original_log_id"` // 옮겨지기 전 Buffer_Log의 log_id
+
+	TransactionType string `db:"transaction_type"`
+	TargetTable     string `db:"target_table"`
+	Payload         string `db:"payload"`
+	PayloadVersion  int    `db:"payload_version"`
+	TargetRecordID  int64  `db:"target_record_id"`
+
+	AttemptCount int64     `db:"attempt_count"`
+	LastError    string    `db:"last_error"`
+	FailedAt     time.Time `db:"failed_at"`
+}
@@ -4,9 +4,23 @@ import "time"
 
 // Restaurant은 식당 자체의 기본 정보를 나타냅니다.
 type Restaurant struct {
-	RestaurantID   int64     // PK
-	RestaurantName string    // 식당 이름
-	LocationRefID  int64     // FK: Location 테이블 참조 (도시/지역 정보)
-	CategoryRefID  int64     // FK: Category 테이블 참조 (음식 종류)
-	CreatedAt      time.Time // 생성일
+	RestaurantID      int64     // PK
+	Owner             int64     // FK: User 테이블 참조 (식당을 등록한 유저)
+	RestaurantName    string    // 식당 이름
+	RestaurantAddress string    // 식당 주소
+	LocationRefID     int64     // FK: Location 테이블 참조 (도시/지역 정보)
+	CategoryRefID     int64     // FK: Category 테이블 참조 (음식 종류)
+	CreatedAt         time.Time // 생성일
+	LastModifiedAt    time.Time // 마지막 수정 시간
+	LastAccessedAt    time.Time // 마지막 조회 시간
+}
+
+// RestaurantPayload는 Restaurant INSERT/UPDATE 버퍼 로그의 payload(JSON) 형태입니다.
+// UPDATE/DELETE 대상 식당은 BufferLog.TargetRecordID로 지정합니다.
+type RestaurantPayload struct {
+	Owner             int64  `json:"owner"`
+	RestaurantName    string `json:"restaurant_name"`
+	RestaurantAddress string `json:"restaurant_address"`
+	LocationRefID     int64  `json:"location_ref_id"`
+	CategoryRefID     int64  `json:"category_ref_id"`
 }
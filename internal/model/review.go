@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// rating의 허용 범위
+const (
+	MinRating = 1.0
+	MaxRating = 5.0
+)
+
+type Review struct {
+	// review_id INTEGER PRIMARY KEY
+	ReviewID int64 `db:"review_id"`
+
+	// restaurant_ref_id INTEGER NOT NULL
+	RestaurantRefID int64 `db:"restaurant_ref_id"`
+
+	// user_ref_id INTEGER NOT NULL
+	UserRefID int64 `db:"user_ref_id"`
+
+	// rating REAL NOT NULL
+	Rating float64 `db:"rating"`
+
+	// review_content TEXT NOT NULL
+	ReviewContent string `db:"review_content"`
+
+	// reliability_weight REAL NOT NULL DEFAULT .5 -- 리뷰 작성 시점의 유저 reliability_score 스냅샷
+	ReliabilityWeight float64 `db:"reliability_weight"`
+
+	// created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now'))
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ReviewPayload는 Review INSERT 버퍼 로그의 payload(JSON) 형태입니다.
+type ReviewPayload struct {
+	RestaurantID      int64   `json:"restaurant_id"`
+	UserID            int64   `json:"user_id"`
+	Rating            float64 `json:"rating"`
+	ReviewContent     string  `json:"review_content"`
+	ReliabilityWeight float64 `json:"reliability_weight"`
+}
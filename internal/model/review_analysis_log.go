@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+)
+
+// Review_Analysis_Log.status 값
+const (
+	AnalysisStatusPending  = "PENDING"  // 분석 대기
+	AnalysisStatusBuffered = "BUFFERED" // 신뢰도 변경이 Buffer_Log에 기록됨
+	AnalysisStatusFailed   = "FAILED"   // 분석 실패
+)
+
+type ReviewAnalysisLog struct {
+	// analysis_log_id INTEGER PRIMARY KEY
+	AnalysisLogID int64 `db:"analysis_log_id"`
+
+	// review_ref_id INTEGER NOT NULL
+	ReviewRefID int64 `db:"review_ref_id"`
+
+	// user_ref_id INTEGER NOT NULL
+	UserRefID int64 `db:"user_ref_id"`
+
+	// change_reliability_score REAL NOT NULL -- 신뢰도 점수를 +- 시키는 정도
+	ChangeReliabilityScore float64 `db:"change_reliability_score"`
+
+	// new_bias_count INTEGER NOT NULL DEFAULT 0 -- 이 리뷰로 늘어나는 극단적 평점 개수
+	NewBiasCount int64 `db:"new_bias_count"`
+
+	// log_updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S', 'now'))
+	LogUpdatedAt time.Time `db:"log_updated_at"`
+
+	// status TEXT NOT NULL DEFAULT 'PENDING'
+	Status string `db:"status"`
+}
+
+// User UPDATE payload의 반영 방식
+const (
+	UserPayloadModeSet   = "set"   // New* 값으로 덮어씀 (버전 1 payload는 mode가 없으면 set)
+	UserPayloadModeDelta = "delta" // *Delta 값을 현재 값에 더함
+)
+
+// UserReliabilityPayload는 User UPDATE 버퍼 로그의 payload(JSON) 형태입니다. (버전 2부터 mode 필수)
+type UserReliabilityPayload struct {
+	Mode           string  `json:"mode"`
+	UserID         int64   `json:"user_id"`
+	NewScore       float64 `json:"new_score"`
+	NewReviewCount int64   `json:"new_review_count"`
+	NewBiasCount   int64   `json:"new_bias_count"`
+
+	// delta 모드에서만 사용
+	ScoreDelta       float64 `json:"score_delta,omitempty"`
+	ReviewCountDelta int64   `json:"review_count_delta,omitempty"`
+	BiasCountDelta   int64   `json:"bias_count_delta,omitempty"`
+}
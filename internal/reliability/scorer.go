@@ -0,0 +1,78 @@
+package reliability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 설정에서 선택할 수 있는 전략 이름
+const (
+	StrategyBiasRatio = "bias_ratio"
+	StrategyConsensus = "consensus"
+	StrategyBayesian  = "bayesian"
+)
+
+// DefaultScore: 근거가 없을 때 사용하는 신뢰도 (User.reliability_score의 DDL 기본값)
+const DefaultScore = 0.5
+
+var ErrUnknownStrategy = errors.New("unknown reliability strategy")
+
+// Input은 신뢰도 계산에 필요한 유저 상태입니다. 카운트는 이번 리뷰까지 반영된 값입니다.
+type Input struct {
+	UserID       int64
+	ReviewCount  int64
+	BiasCount    int64
+	CurrentScore float64
+}
+
+// ReliabilityScorer는 유저의 새 reliability_score(0.0 ~ 1.0)를 계산하는 전략입니다.
+type ReliabilityScorer interface {
+	Name() string
+	Score(ctx context.Context, in Input) (float64, error)
+}
+
+// Config: 사용할 전략과 전략별 파라미터 (0이면 기본값 사용)
+type Config struct {
+	Strategy string
+
+	// Bayesian: Beta(PriorAlpha, PriorBeta) 사전분포
+	PriorAlpha float64
+	PriorBeta  float64
+
+	// Consensus: 이 값만큼 벗어나면 신뢰도 0 (1~5점 평점이면 최대 4)
+	MaxDeviation float64
+}
+
+// New: 설정에 맞는 전략을 생성합니다. consensus 전략은 source가 필요합니다.
+func New(cfg Config, source ConsensusSource) (ReliabilityScorer, error) {
+	switch cfg.Strategy {
+	case "", StrategyBiasRatio:
+		return BiasRatioScorer{}, nil
+	case StrategyConsensus:
+		if source == nil {
+			return nil, fmt.Errorf("%s strategy requires a consensus source", StrategyConsensus)
+		}
+		return ConsensusScorer{Source: source, MaxDeviation: cfg.MaxDeviation}, nil
+	case StrategyBayesian:
+		return BayesianScorer{Alpha: cfg.PriorAlpha, Beta: cfg.PriorBeta}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, cfg.Strategy)
+	}
+}
+
+// IsExtremeRating: 1점 또는 5점 같은 극단적 평점인지 판단합니다. (bias_count 집계 기준)
+func IsExtremeRating(rating float64) bool {
+	return rating <= 1 || rating >= 5
+}
+
+// Clamp: 점수를 스키마가 정의한 0.0 ~ 1.0 범위로 자릅니다.
+func Clamp(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
@@ -0,0 +1,71 @@
+package reliability_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"restaurant_db/internal/reliability"
+)
+
+type fakeSource struct {
+	deviation float64
+	compared  int64
+}
+
+func (f fakeSource) ConsensusDeviation(context.Context, int64) (float64, int64, error) {
+	return f.deviation, f.compared, nil
+}
+
+func TestStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		scorer reliability.ReliabilityScorer
+		in     reliability.Input
+		want   float64
+	}{
+		{"bias ratio", reliability.BiasRatioScorer{}, reliability.Input{ReviewCount: 4, BiasCount: 1}, 0.75},
+		{"bias ratio without reviews", reliability.BiasRatioScorer{}, reliability.Input{}, reliability.DefaultScore},
+		{"bias ratio clamped", reliability.BiasRatioScorer{}, reliability.Input{ReviewCount: 1, BiasCount: 3}, 0},
+		{"consensus", reliability.ConsensusScorer{Source: fakeSource{deviation: 1, compared: 3}}, reliability.Input{}, 0.75},
+		{"consensus keeps score without comparisons", reliability.ConsensusScorer{Source: fakeSource{}}, reliability.Input{CurrentScore: 0.6}, 0.6},
+		{"consensus clamped", reliability.ConsensusScorer{Source: fakeSource{deviation: 9, compared: 1}}, reliability.Input{}, 0},
+		{"bayesian prior mean", reliability.BayesianScorer{}, reliability.Input{}, 0.5},
+		{"bayesian posterior", reliability.BayesianScorer{}, reliability.Input{ReviewCount: 8, BiasCount: 0}, 0.9},
+		{"bayesian custom prior", reliability.BayesianScorer{Alpha: 3, Beta: 1}, reliability.Input{ReviewCount: 4, BiasCount: 4}, 0.375},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scorer.Score(ctx, tt.in)
+			if err != nil {
+				t.Fatalf("Score failed: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected %.3f, got %.3f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewSelectsStrategy(t *testing.T) {
+	for _, name := range []string{"", reliability.StrategyBiasRatio, reliability.StrategyConsensus, reliability.StrategyBayesian} {
+		scorer, err := reliability.New(reliability.Config{Strategy: name}, fakeSource{})
+		if err != nil {
+			t.Fatalf("New(%q) failed: %v", name, err)
+		}
+		if name != "" && scorer.Name() != name {
+			t.Errorf("Expected strategy %q, got %q", name, scorer.Name())
+		}
+	}
+
+	if _, err := reliability.New(reliability.Config{Strategy: "magic"}, nil); !errors.Is(err, reliability.ErrUnknownStrategy) {
+		t.Errorf("Expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, err := reliability.New(reliability.Config{Strategy: reliability.StrategyConsensus}, nil); err == nil {
+		t.Errorf("Expected error for consensus strategy without source")
+	}
+}
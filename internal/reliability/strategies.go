@@ -0,0 +1,75 @@
+package reliability
+
+import (
+	"context"
+	"fmt"
+)
+
+// BiasRatioScorer: 전체 리뷰 중 극단적 평점(bias_count)이 아닌 비율을 신뢰도로 사용합니다.
+type BiasRatioScorer struct{}
+
+func (BiasRatioScorer) Name() string { return StrategyBiasRatio }
+
+func (BiasRatioScorer) Score(_ context.Context, in Input) (float64, error) {
+	if in.ReviewCount <= 0 {
+		return DefaultScore, nil
+	}
+	return Clamp(1 - float64(in.BiasCount)/float64(in.ReviewCount)), nil
+}
+
+// ConsensusSource: 유저 평점이 식당별 합의(다른 유저 평균)에서 얼마나 벗어나는지 제공합니다.
+// repository.ReviewRepository가 구현합니다.
+type ConsensusSource interface {
+	// ConsensusDeviation: 비교 가능한 리뷰들의 평균 절대 편차와 그 리뷰 개수
+	ConsensusDeviation(ctx context.Context, userID int64) (float64, int64, error)
+}
+
+// ConsensusScorer: 식당 합의 평점과의 평균 편차가 클수록 신뢰도를 낮춥니다.
+type ConsensusScorer struct {
+	Source       ConsensusSource
+	MaxDeviation float64
+}
+
+func (ConsensusScorer) Name() string { return StrategyConsensus }
+
+func (s ConsensusScorer) Score(ctx context.Context, in Input) (float64, error) {
+	deviation, compared, err := s.Source.ConsensusDeviation(ctx, in.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute consensus deviation: %w", err)
+	}
+	if compared == 0 {
+		// 비교할 다른 리뷰가 없으면 판단을 보류하고 현재 점수 유지
+		return Clamp(in.CurrentScore), nil
+	}
+
+	maxDeviation := s.MaxDeviation
+	if maxDeviation <= 0 {
+		maxDeviation = 4 // 1 ~ 5점 평점의 최대 편차
+	}
+	return Clamp(1 - deviation/maxDeviation), nil
+}
+
+// BayesianScorer: 극단적이지 않은 리뷰를 성공으로 보는 Beta-Binomial 모델의 사후 평균입니다.
+// 리뷰가 적은 유저는 사전분포 평균(기본 Beta(1,1) = 0.5) 근처에 머물고, 리뷰가 쌓일수록 관측 비율에 수렴합니다.
+type BayesianScorer struct {
+	Alpha float64
+	Beta  float64
+}
+
+func (BayesianScorer) Name() string { return StrategyBayesian }
+
+func (s BayesianScorer) Score(_ context.Context, in Input) (float64, error) {
+	alpha, beta := s.Alpha, s.Beta
+	if alpha <= 0 {
+		alpha = 1
+	}
+	if beta <= 0 {
+		beta = 1
+	}
+
+	good := float64(in.ReviewCount - in.BiasCount)
+	if good < 0 {
+		good = 0
+	}
+	return Clamp((alpha + good) / (alpha + beta + float64(in.ReviewCount))), nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/model"
+)
+
+// AnalysisLogRepository: Review_Analysis_Log 테이블에 접근합니다.
+type AnalysisLogRepository interface {
+	// Create: 리뷰 한 건에 대한 신뢰도 분석 요청을 PENDING 상태로 추가
+	Create(ctx context.Context, analysis *model.ReviewAnalysisLog) error
+
+	// GetPending: 아직 처리되지 않은(PENDING) 분석 요청을 오래된 순으로 가져옴
+	GetPending(ctx context.Context, limit int) ([]model.ReviewAnalysisLog, error)
+
+	// MarkBuffered: 계산된 신뢰도 변화량을 기록하고 BUFFERED로 표시
+	MarkBuffered(ctx context.Context, analysisLogID int64, change float64) error
+
+	// MarkFailed: 분석에 실패한 요청을 FAILED로 표시
+	MarkFailed(ctx context.Context, analysisLogID int64) error
+}
+
+type AnalysisLogRepoImpl struct {
+	DB     DBTX
+	Logger *slog.Logger // 생성/상태 변경 이벤트
+}
+
+func NewAnalysisLogRepository(db DBTX, opts ...Option) AnalysisLogRepository {
+	return &AnalysisLogRepoImpl{DB: db, Logger: componentLogger(opts, "analysis_repository")}
+}
+
+func (r *AnalysisLogRepoImpl) Create(ctx context.Context, analysis *model.ReviewAnalysisLog) error {
+	query := `
+		INSERT INTO Review_Analysis_Log (
+			review_ref_id, user_ref_id, change_reliability_score, new_bias_count
+		) VALUES (?, ?, ?, ?)` // status는 DDL 기본값(PENDING)을 사용
+
+	result, err := r.DB.ExecContext(
+		ctx,
+		query,
+		analysis.ReviewRefID,
+		analysis.UserRefID,
+		analysis.ChangeReliabilityScore,
+		analysis.NewBiasCount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create analysis log: %w", err)
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read inserted analysis log ID: %w", err)
+	}
+	analysis.AnalysisLogID = lastID
+	analysis.Status = model.AnalysisStatusPending
+	r.Logger.Debug("analysis requested", slog.Int64("analysis_log_id", analysis.AnalysisLogID),
+		slog.Int64("review_id", analysis.ReviewRefID), logging.UserID(analysis.UserRefID))
+	return nil
+}
+
+func (r *AnalysisLogRepoImpl) GetPending(ctx context.Context, limit int) ([]model.ReviewAnalysisLog, error) {
+	query := `
+		SELECT
+			analysis_log_id, review_ref_id, user_ref_id, change_reliability_score,
+			new_bias_count, log_updated_at, status
+		FROM Review_Analysis_Log
+		WHERE status = ?
+		ORDER BY analysis_log_id
+		LIMIT ?`
+
+	rows, err := r.DB.QueryContext(ctx, query, model.AnalysisStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending analysis logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []model.ReviewAnalysisLog
+	for rows.Next() {
+		var analysis model.ReviewAnalysisLog
+		var logUpdatedAtStr string
+
+		err := rows.Scan(
+			&analysis.AnalysisLogID,
+			&analysis.ReviewRefID,
+			&analysis.UserRefID,
+			&analysis.ChangeReliabilityScore,
+			&analysis.NewBiasCount,
+			&logUpdatedAtStr,
+			&analysis.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		analysis.LogUpdatedAt, err = time.Parse(sqliteTimeFormat, logUpdatedAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse analysis log_updated_at: %w", err)
+		}
+		logs = append(logs, analysis)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return logs, nil
+}
+
+func (r *AnalysisLogRepoImpl) MarkBuffered(ctx context.Context, analysisLogID int64, change float64) error {
+	query := `
+		UPDATE Review_Analysis_Log
+		SET
+			change_reliability_score = ?,
+			status = ?,
+			log_updated_at = strftime('%Y-%m-%d %H:%M:%S', 'now')
+		WHERE analysis_log_id = ?`
+
+	_, err := r.DB.ExecContext(ctx, query, change, model.AnalysisStatusBuffered, analysisLogID)
+	if err != nil {
+		return fmt.Errorf("failed to mark analysis log %d buffered: %w", analysisLogID, err)
+	}
+	r.Logger.Debug("analysis buffered", slog.Int64("analysis_log_id", analysisLogID), slog.Float64("change", change))
+	return nil
+}
+
+func (r *AnalysisLogRepoImpl) MarkFailed(ctx context.Context, analysisLogID int64) error {
+	query := `
+		UPDATE Review_Analysis_Log
+		SET
+			status = ?,
+			log_updated_at = strftime('%Y-%m-%d %H:%M:%S', 'now')
+		WHERE analysis_log_id = ?`
+
+	_, err := r.DB.ExecContext(ctx, query, model.AnalysisStatusFailed, analysisLogID)
+	if err != nil {
+		return fmt.Errorf("failed to mark analysis log %d failed: %w", analysisLogID, err)
+	}
+	r.Logger.Warn("analysis marked failed", slog.Int64("analysis_log_id", analysisLogID))
+	return nil
+}
@@ -5,13 +5,22 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"restaurant_db/internal/logging"
 	"restaurant_db/internal/model"
+	"sort"
 	"strings"
 	"time"
 )
 
+// ErrLeaseLost: 처리 중인 로그의 lease가 만료되어 다른 Worker가 가져갔을 때 반환됩니다.
+var ErrLeaseLost = errors.New("buffer log lease lost")
+
+// ErrInvalidLog: AddLog의 검증기가 로그를 거절했을 때 반환됩니다. (검증기의 오류를 함께 감쌈)
+var ErrInvalidLog = errors.New("invalid buffer log")
+
 type BufferRepository interface {
-	// 새로운 쓰기 명령을 Buffer_Log 테이블에 추가
+	// 새로운 쓰기 명령을 Buffer_Log 테이블에 추가 (성공 시 log.LogID가 할당됨, 같은 멱등성 키의 재시도면 처음 로그의 ID)
 	AddLog(ctx context.Context, log *model.BufferLog) error
 
 	// pending중인 로그 목록을 가져옴, 즉 db에 반영이 아직 되지 않은 로그들을 가져오는 것
@@ -19,49 +28,244 @@ type BufferRepository interface {
 
 	// is_committed = 1로 업데이트 하는 메소드, 커밋 상태를 업데이트하는 함수
 	UpdateCommitted(ctx context.Context, logIDs []int64) error
+
+	// 반영 실패를 기록 (attempt_count + 1, last_error, next_retry_at 이전에는 GetPendingLogs에서 제외)
+	RecordFailure(ctx context.Context, logID int64, lastError string, nextRetryAt time.Time) error
+
+	// 재시도를 포기한 로그를 Buffer_Dead_Letter로 옮김 (Buffer_Log에서는 삭제)
+	MoveToDeadLetter(ctx context.Context, logID int64) error
+
+	// 처리 가능한 로그를 최대 limit개 workerID 이름으로 lease 동안 가져감 (여러 Worker가 동시에 호출해도 겹치지 않음)
+	ClaimBatch(ctx context.Context, workerID string, lease time.Duration, limit int) ([]model.BufferLog, error)
+
+	// workerID가 아직 claim을 가지고 있는 로그만 커밋 처리 (하나라도 빼앗겼으면 ErrLeaseLost)
+	CommitClaimed(ctx context.Context, workerID string, logIDs []int64) error
+
+	// workerID의 claim을 풀어 다른 Worker가 바로 가져갈 수 있게 함 (처리를 포기할 때 사용)
+	ReleaseClaims(ctx context.Context, workerID string, logIDs []int64) error
+
+	// 아직 반영되지 않은 로그 수 (재시도 대기와 다른 Worker가 가져간 로그 포함)
+	CountPending(ctx context.Context) (int64, error)
+
+	// 아직 반영되지 않은 로그 중 가장 오래된 log_updated_at (반영 지연 측정용, 없으면 nil)
+	OldestPendingAt(ctx context.Context) (*time.Time, error)
+
+	// table의 recordID 행을 대상으로 아직 반영되지 않은 로그를 log_id 순으로 가져옴 (read-your-writes 조회용)
+	GetPendingForRecord(ctx context.Context, table string, recordID int64) ([]model.BufferLog, error)
+
+	// restaurantID 식당의 평점 요약에 영향을 주지만 아직 반영되지 않은 로그 수 (그 식당의 Review INSERT, Restaurant UPDATE/DELETE)
+	CountPendingForRestaurant(ctx context.Context, restaurantID int64) (int64, error)
+
+	// before보다 먼저 추가되어 이미 반영된 로그를 log_id 순으로 최대 limit개 가져옴 (압축 대상)
+	GetCommittedBefore(ctx context.Context, before time.Time, limit int) ([]model.BufferLog, error)
+
+	// 반영된 로그만 지우고 지운 행 수를 반환 (아직 반영되지 않은 로그는 건드리지 않음)
+	DeleteCommitted(ctx context.Context, logIDs []int64) (int64, error)
+
+	// 보관 기간이 지난 멱등성 키를 지우고 지운 개수를 반환
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
 }
 
+// PayloadValidator: AddLog가 로그를 저장하기 전에 호출하는 검증기 (buffer.Registry가 구현)
+// 비어 있는 PayloadVersion을 채우는 등 로그를 고칠 수 있도록 포인터를 받습니다.
+type PayloadValidator interface {
+	Validate(log *model.BufferLog) error
+}
+
+// LogNotifier: AddLog가 새 로그를 추가한 뒤(트랜잭션 커밋 후) 호출됩니다. (CheckpointWorker를 깨우는 프로세스 내 신호)
+// AddLog를 막지 않도록 빨리 반환해야 합니다.
+type LogNotifier interface {
+	LogAdded(log model.BufferLog)
+}
+
+// DefaultIdempotencyTTL: 멱등성 키를 기억하는 기본 기간 (이 기간 안의 같은 키 요청은 중복으로 취급)
+const DefaultIdempotencyTTL = 24 * time.Hour
+
 type BufferRepoImpl struct {
-	DB *sql.DB
+	DB        DBTX
+	Validator PayloadValidator // nil이면 검증하지 않음
+	// IdempotencyTTL: AddLog에 넘긴 멱등성 키를 보관하는 기간
+	IdempotencyTTL time.Duration
+	Notifier       LogNotifier // nil이면 알리지 않음
+	// Logger: 추가/거절/중복/Dead Letter 이벤트 (기본값은 slog.Default)
+	Logger *slog.Logger
+}
+
+// BufferOption: NewBufferRepository에 넘기는 선택 설정
+type BufferOption func(*BufferRepoImpl)
+
+// WithValidator: AddLog가 v를 통과한 로그만 저장하도록 합니다.
+func WithValidator(v PayloadValidator) BufferOption {
+	return func(r *BufferRepoImpl) { r.Validator = v }
 }
 
-func NewBufferRepository(db *sql.DB) BufferRepository {
-	return &BufferRepoImpl{DB: db}
+// WithNotifier: 새 로그가 추가될 때마다 n에 알립니다. (멱등성 키로 걸러진 중복 요청은 알리지 않음)
+func WithNotifier(n LogNotifier) BufferOption {
+	return func(r *BufferRepoImpl) { r.Notifier = n }
 }
 
+// WithLogger: 이벤트를 logger로 기록합니다.
+func WithLogger(logger *slog.Logger) BufferOption {
+	return func(r *BufferRepoImpl) { r.Logger = logging.Component(logger, "buffer_repository") }
+}
+
+// WithIdempotencyTTL: 멱등성 키 보관 기간을 바꿉니다. (기본값 DefaultIdempotencyTTL)
+func WithIdempotencyTTL(ttl time.Duration) BufferOption {
+	return func(r *BufferRepoImpl) { r.IdempotencyTTL = ttl }
+}
+
+func NewBufferRepository(db DBTX, opts ...BufferOption) BufferRepository {
+	return newBufferRepo(db, opts...)
+}
+
+// newBufferRepo: 기본값에 opts를 적용한 BufferRepoImpl (DeadLetterRepository의 재투입 설정에도 사용)
+func newBufferRepo(db DBTX, opts ...BufferOption) *BufferRepoImpl {
+	r := &BufferRepoImpl{
+		DB:             db,
+		IdempotencyTTL: DefaultIdempotencyTTL,
+		Logger:         logging.Component(nil, "buffer_repository"),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// AddLog: 로그를 Buffer_Log에 추가하고 LogID를 채웁니다.
+// IdempotencyKey가 있고 보관 기간 안에 같은 키로 추가된 로그가 있으면, 새 행을 만들지 않고 그 로그의 ID를 채웁니다.
+// 검증기가 없으면 PayloadVersion을 그대로 저장하며, 0(버전 없음)은 반영할 때 현재 버전의 형태로만 해석됩니다.
 func (r *BufferRepoImpl) AddLog(ctx context.Context, log *model.BufferLog) error {
 	if log.IsCommitted != 0 {
 		return errors.New("it is already committed")
 	}
+	if r.Validator != nil {
+		if err := r.Validator.Validate(log); err != nil {
+			r.Logger.Warn("buffer log rejected", slog.String("target_table", log.TargetTable),
+				slog.String("transaction_type", log.TransactionType), logging.Err(err))
+			return fmt.Errorf("%w: %w", ErrInvalidLog, err)
+		}
+	}
+
+	if log.IdempotencyKey == "" {
+		logID, err := insertBufferLog(ctx, r.DB, log)
+		if err != nil {
+			return err
+		}
+		log.LogID = logID
+		r.notify(*log)
+		return nil
+	}
+
+	var logID int64
+	var inserted bool
+	err := inTx(ctx, r.DB, func(db DBTX) error {
+		// 1. 키를 먼저 차지 (만료된 같은 키만 덮어씀)
+		// 쓰기로 시작해야 같은 키의 동시 재시도가 읽기 잠금에서 쓰기 잠금으로 올리다 SQLITE_BUSY로 실패하지 않고 앞 요청을 기다림
+		result, err := db.ExecContext(ctx, `
+		INSERT INTO Buffer_Idempotency_Key (idempotency_key, log_id, expires_at)
+		VALUES (?, 0, ?)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			log_id = excluded.log_id,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+		WHERE Buffer_Idempotency_Key.expires_at <= excluded.created_at`,
+			log.IdempotencyKey, time.Now().Add(r.IdempotencyTTL).UTC().Format(sqliteTimeFormat))
+		if err != nil {
+			return fmt.Errorf("failed to record idempotency key: %w", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to record idempotency key: %w", err)
+		}
+
+		// 2. 아직 보관 중인 같은 키가 있으면 로그를 추가하지 않고 처음 추가된 로그 ID를 반환
+		if claimed == 0 {
+			err := db.QueryRowContext(ctx, `SELECT log_id FROM Buffer_Idempotency_Key WHERE idempotency_key = ?`,
+				log.IdempotencyKey).Scan(&logID)
+			if err != nil {
+				return fmt.Errorf("failed to look up idempotency key: %w", err)
+			}
+			return nil
+		}
+
+		// 3. 새 로그를 추가하고 키에 그 ID를 기록
+		if logID, err = insertBufferLog(ctx, db, log); err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE Buffer_Idempotency_Key SET log_id = ? WHERE idempotency_key = ?`,
+			logID, log.IdempotencyKey); err != nil {
+			return fmt.Errorf("failed to record idempotency key: %w", err)
+		}
+		inserted = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
+	log.LogID = logID
+	if inserted {
+		r.notify(*log)
+	} else {
+		r.Logger.Debug("duplicate buffer log ignored", logging.LogID(logID), slog.String("idempotency_key", log.IdempotencyKey))
+	}
+	return nil
+}
+
+// notify: Notifier가 있으면 새 로그를 알립니다.
+// (r.DB가 호출자의 트랜잭션이면 커밋 전에 알려지므로, Worker가 그때 못 본 로그는 다음 폴링에서 반영됨)
+func (r *BufferRepoImpl) notify(log model.BufferLog) {
+	r.Logger.Debug("buffer log added", logging.LogID(log.LogID), slog.String("target_table", log.TargetTable))
+	if r.Notifier != nil {
+		r.Notifier.LogAdded(log)
+	}
+}
+
+// insertBufferLog: Buffer_Log에 한 행을 추가하고 할당된 log_id를 반환합니다.
+func insertBufferLog(ctx context.Context, db DBTX, log *model.BufferLog) (int64, error) {
 	query := `
 	INSERT INTO Buffer_Log (
 	transaction_type, 
 	target_table,
 	payload, 
+	payload_version,
 	target_record_id
-	) VALUES (?, ?, ?, ?)`
+	) VALUES (?, ?, ?, ?, ?)`
 
-	_, err := r.DB.ExecContext(
+	result, err := db.ExecContext(
 		ctx,
 		query,
 		log.TransactionType,
 		log.TargetTable,
 		log.Payload,
+		log.PayloadVersion,
 		log.TargetRecordID,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert log: %w", err)
+		return 0, fmt.Errorf("failed to insert log: %w", err)
 	}
 
-	return nil
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read inserted log ID: %w", err)
+	}
+	return lastID, nil
 }
 
+// bufferLogColumns: Buffer_Log 조회 시 scanBufferLog가 기대하는 컬럼 순서
+const bufferLogColumns = `
+	log_id, transaction_type, target_table, payload, payload_version, target_record_id,
+	log_updated_at, is_committed, attempt_count, last_error, next_retry_at,
+	claimed_by, lease_expires_at`
+
+// GetPendingLogs: 아직 반영되지 않았고 재시도 대기 중이 아닌 로그를 log_id(FIFO) 순으로 가져옵니다.
 func (r *BufferRepoImpl) GetPendingLogs(ctx context.Context, limit int) ([]model.BufferLog, error) {
 	query := `
-	SELECT *
+	SELECT ` + bufferLogColumns + `
 	FROM Buffer_Log
 	WHERE is_committed = 0
+	  AND (next_retry_at IS NULL OR next_retry_at <= strftime('%Y-%m-%d %H:%M:%S', 'now'))
+	ORDER BY log_id
 	LIMIT ?`
 
 	rows, err := r.DB.QueryContext(ctx, query, limit)
@@ -74,33 +278,66 @@ func (r *BufferRepoImpl) GetPendingLogs(ctx context.Context, limit int) ([]model
 	var logs []model.BufferLog
 
 	for rows.Next() {
-		var log model.BufferLog
-		var targetRecordID sql.NullInt64
-		var logUpdatedAtStr string
-
-		err := rows.Scan(
-			&log.LogID,
-			&log.TransactionType,
-			&log.TargetTable,
-			&log.Payload,
-			&targetRecordID,
-			&logUpdatedAtStr,
-			&log.IsCommitted,
-		)
+		log, err := scanBufferLog(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return nil, err
 		}
-		const sqliteTimeFormat = "2006-01-02 15:04:05"
-		parsedTime, err := time.Parse(sqliteTimeFormat, logUpdatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse log updated at: %w", err)
-		}
-		log.LogUpdatedAt = parsedTime
+		logs = append(logs, *log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return logs, nil
+}
+
+func (r *BufferRepoImpl) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM Buffer_Log WHERE is_committed = 0`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending logs: %w", err)
+	}
+	return count, nil
+}
+
+// OldestPendingAt: log_updated_at은 추가될 때만 정해지므로(실패 기록은 바꾸지 않음) 재시도 중인 로그도 처음 추가된 시각 기준입니다.
+func (r *BufferRepoImpl) OldestPendingAt(ctx context.Context) (*time.Time, error) {
+	var oldestStr sql.NullString
+	err := r.DB.QueryRowContext(ctx, `SELECT MIN(log_updated_at) FROM Buffer_Log WHERE is_committed = 0`).Scan(&oldestStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oldest pending log: %w", err)
+	}
+	if !oldestStr.Valid {
+		return nil, nil // 반영 대기 로그 없음
+	}
+
+	oldest, err := time.Parse(sqliteTimeFormat, oldestStr.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse oldest pending log_updated_at: %w", err)
+	}
+	return &oldest, nil
+}
+
+// GetPendingForRecord: 재시도 대기 중인 로그도 결국 반영될 것이므로 함께 가져옵니다. (idx_buffer_record 사용)
+func (r *BufferRepoImpl) GetPendingForRecord(ctx context.Context, table string, recordID int64) ([]model.BufferLog, error) {
+	query := `
+	SELECT ` + bufferLogColumns + `
+	FROM Buffer_Log
+	WHERE target_table = ? AND target_record_id = ? AND is_committed = 0
+	ORDER BY log_id`
 
-		if targetRecordID.Valid {
-			log.TargetRecordID = targetRecordID.Int64
+	rows, err := r.DB.QueryContext(ctx, query, table, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending logs for %s %d: %w", table, recordID, err)
+	}
+	defer rows.Close()
+
+	var logs []model.BufferLog
+	for rows.Next() {
+		log, err := scanBufferLog(rows)
+		if err != nil {
+			return nil, err
 		}
-		logs = append(logs, log)
+		logs = append(logs, *log)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to iterate rows: %w", err)
@@ -109,26 +346,82 @@ func (r *BufferRepoImpl) GetPendingLogs(ctx context.Context, limit int) ([]model
 	return logs, nil
 }
 
+// CountPendingForRestaurant: Review 로그는 target_record_id가 없으므로 payload의 restaurant_id로 찾습니다.
+// (JSON이 아닌 payload는 json_extract가 실패하므로 json_valid로 먼저 거름)
+func (r *BufferRepoImpl) CountPendingForRestaurant(ctx context.Context, restaurantID int64) (int64, error) {
+	query := `
+	SELECT COUNT(*)
+	FROM Buffer_Log
+	WHERE is_committed = 0
+	  AND (
+		(target_table = 'Restaurant' AND target_record_id = ?)
+		OR (target_table = 'Review'
+			AND CASE WHEN json_valid(payload) THEN json_extract(payload, '$.restaurant_id') END = ?)
+	  )`
+
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, query, restaurantID, restaurantID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending logs for restaurant %d: %w", restaurantID, err)
+	}
+	return count, nil
+}
+
+// scanBufferLog: bufferLogColumns 순서대로 한 행을 읽고 NULL 허용 컬럼과 시간 필드를 변환합니다.
+func scanBufferLog(row rowScanner) (*model.BufferLog, error) {
+	var log model.BufferLog
+	var targetRecordID sql.NullInt64
+	var lastError, nextRetryAtStr, claimedBy, leaseExpiresAtStr sql.NullString
+	var logUpdatedAtStr string
+
+	err := row.Scan(
+		&log.LogID,
+		&log.TransactionType,
+		&log.TargetTable,
+		&log.Payload,
+		&log.PayloadVersion,
+		&targetRecordID,
+		&logUpdatedAtStr,
+		&log.IsCommitted,
+		&log.AttemptCount,
+		&lastError,
+		&nextRetryAtStr,
+		&claimedBy,
+		&leaseExpiresAtStr,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	if log.LogUpdatedAt, err = time.Parse(sqliteTimeFormat, logUpdatedAtStr); err != nil {
+		return nil, fmt.Errorf("failed to parse log updated at: %w", err)
+	}
+	if nextRetryAtStr.Valid {
+		if log.NextRetryAt, err = time.Parse(sqliteTimeFormat, nextRetryAtStr.String); err != nil {
+			return nil, fmt.Errorf("failed to parse log next_retry_at: %w", err)
+		}
+	}
+	if leaseExpiresAtStr.Valid {
+		if log.LeaseExpiresAt, err = time.Parse(sqliteTimeFormat, leaseExpiresAtStr.String); err != nil {
+			return nil, fmt.Errorf("failed to parse log lease_expires_at: %w", err)
+		}
+	}
+
+	log.TargetRecordID = targetRecordID.Int64
+	log.LastError = lastError.String
+	log.ClaimedBy = claimedBy.String
+	return &log, nil
+}
+
 func (r *BufferRepoImpl) UpdateCommitted(ctx context.Context, logIDs []int64) error {
 	if len(logIDs) == 0 {
 		return nil
 	}
 
-	placeholders := make([]string, len(logIDs))
-	for i := range logIDs {
-		placeholders[i] = "?"
-	}
-	// 여기서 에러는 아직 db가 만들어지지 않아서 생기는 오류, 테스트 코드에서 db를 만드니까 상관 없음
-	// 추후 db.go 를 추가해 db생성 코드를 짤 예정
+	placeholders, args := inClause(logIDs)
 	query := `
 	UPDATE Buffer_Log
 	SET is_committed = 1
-	WHERE log_id IN (` + strings.Join(placeholders, ",") + `)`
-
-	args := make([]interface{}, len(logIDs))
-	for i, id := range logIDs {
-		args[i] = id
-	}
+	WHERE log_id IN (` + placeholders + `)`
 
 	_, err := r.DB.ExecContext(ctx, query, args...)
 	if err != nil {
@@ -137,3 +430,233 @@ func (r *BufferRepoImpl) UpdateCommitted(ctx context.Context, logIDs []int64) er
 
 	return nil
 }
+
+// RecordFailure: 반영에 실패한 로그의 시도 횟수와 오류를 기록하고 nextRetryAt까지 재시도를 미룹니다. (claim도 해제)
+// next_retry_at은 초 단위로 저장되므로 미래 시각은 올림해서, 잘린 초 때문에 백오프가 짧아지지 않게 합니다. (이미 지난 시각은 바로 재시도)
+func (r *BufferRepoImpl) RecordFailure(ctx context.Context, logID int64, lastError string, nextRetryAt time.Time) error {
+	query := `
+	UPDATE Buffer_Log
+	SET attempt_count = attempt_count + 1,
+		last_error = ?,
+		next_retry_at = ?,
+		claimed_by = NULL,
+		lease_expires_at = NULL
+	WHERE log_id = ?`
+
+	if truncated := nextRetryAt.Truncate(time.Second); truncated.Before(nextRetryAt) && nextRetryAt.After(time.Now()) {
+		nextRetryAt = truncated.Add(time.Second)
+	}
+
+	result, err := r.DB.ExecContext(ctx, query, lastError, nextRetryAt.UTC().Format(sqliteTimeFormat), logID)
+	if err != nil {
+		return fmt.Errorf("failed to record failure for log %d: %w", logID, err)
+	}
+	return requireAffected(result, logID)
+}
+
+// MoveToDeadLetter: 로그를 Buffer_Dead_Letter에 복사하고 Buffer_Log에서 지웁니다. (두 문장을 하나의 트랜잭션으로 실행)
+func (r *BufferRepoImpl) MoveToDeadLetter(ctx context.Context, logID int64) error {
+	return inTx(ctx, r.DB, func(db DBTX) error {
+		result, err := db.ExecContext(ctx, `
+		INSERT INTO Buffer_Dead_Letter (
+			original_log_id, transaction_type, target_table, payload, payload_version, target_record_id,
+			attempt_count, last_error
+		)
+		SELECT log_id, transaction_type, target_table, payload, payload_version, target_record_id,
+			attempt_count, COALESCE(last_error, '')
+		FROM Buffer_Log
+		WHERE log_id = ? AND is_committed = 0`, logID)
+		if err != nil {
+			return fmt.Errorf("failed to copy log %d to dead letter: %w", logID, err)
+		}
+		if err := requireAffected(result, logID); err != nil {
+			return err
+		}
+
+		if _, err := db.ExecContext(ctx, `DELETE FROM Buffer_Log WHERE log_id = ?`, logID); err != nil {
+			return fmt.Errorf("failed to remove dead-lettered log %d: %w", logID, err)
+		}
+		r.Logger.Warn("buffer log moved to dead letter", logging.LogID(logID))
+		return nil
+	})
+}
+
+// ClaimBatch: 커밋되지 않았고, 재시도 대기 중이 아니며, 다른 Worker의 lease가 살아 있지 않은 로그를
+// UPDATE ... RETURNING 한 문장으로 log_id 순으로 가져갑니다. SQLite는 쓰기를 직렬화하므로 두 Worker가 같은 로그를 가져갈 수 없습니다.
+// 같은 레코드(target_table, target_record_id)에 지금 가져갈 수 없는 앞선 로그(다른 Worker가 처리 중이거나 재시도 대기 중)가 있으면
+// 그 뒤의 로그도 가져가지 않으므로, 여러 Worker가 동시에 돌아도 레코드별 반영 순서가 유지됩니다.
+func (r *BufferRepoImpl) ClaimBatch(ctx context.Context, workerID string, lease time.Duration, limit int) ([]model.BufferLog, error) {
+	leaseExpiresAt := time.Now().Add(lease).UTC().Format(sqliteTimeFormat)
+
+	query := `
+	UPDATE Buffer_Log
+	SET claimed_by = ?, lease_expires_at = ?
+	WHERE log_id IN (
+		SELECT b.log_id
+		FROM Buffer_Log b
+		WHERE b.is_committed = 0
+		  AND (b.next_retry_at IS NULL OR b.next_retry_at <= strftime('%Y-%m-%d %H:%M:%S', 'now'))
+		  AND (b.lease_expires_at IS NULL OR b.lease_expires_at <= strftime('%Y-%m-%d %H:%M:%S', 'now'))
+		  AND (COALESCE(b.target_record_id, 0) = 0 OR NOT EXISTS (
+			SELECT 1
+			FROM Buffer_Log e
+			WHERE e.is_committed = 0
+			  AND e.target_table = b.target_table
+			  AND e.target_record_id = b.target_record_id
+			  AND e.log_id < b.log_id
+			  AND (
+				e.next_retry_at > strftime('%Y-%m-%d %H:%M:%S', 'now')
+				OR e.lease_expires_at > strftime('%Y-%m-%d %H:%M:%S', 'now')
+			  )
+		  ))
+		ORDER BY b.log_id
+		LIMIT ?
+	)
+	RETURNING ` + bufferLogColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, workerID, leaseExpiresAt, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim logs for %s: %w", workerID, err)
+	}
+	defer rows.Close()
+
+	var logs []model.BufferLog
+	for rows.Next() {
+		log, err := scanBufferLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate claimed logs: %w", err)
+	}
+
+	// RETURNING의 순서는 보장되지 않으므로 log_id 순으로 정렬
+	sort.Slice(logs, func(i, j int) bool { return logs[i].LogID < logs[j].LogID })
+	return logs, nil
+}
+
+// CommitClaimed: workerID가 claim을 유지하고 있는 로그만 is_committed = 1로 바꾸고 claim을 해제합니다.
+// 일부 로그의 lease를 다른 Worker가 가져갔다면 ErrLeaseLost를 반환하므로, 호출자는 트랜잭션을 롤백해야 합니다.
+func (r *BufferRepoImpl) CommitClaimed(ctx context.Context, workerID string, logIDs []int64) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(logIDs)
+	query := `
+	UPDATE Buffer_Log
+	SET is_committed = 1, claimed_by = NULL, lease_expires_at = NULL
+	WHERE claimed_by = ? AND log_id IN (` + placeholders + `)`
+
+	result, err := r.DB.ExecContext(ctx, query, append([]any{workerID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to commit claimed logs: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if affected != int64(len(logIDs)) {
+		return fmt.Errorf("%s committed %d of %d logs: %w", workerID, affected, len(logIDs), ErrLeaseLost)
+	}
+	return nil
+}
+
+// ReleaseClaims: workerID가 가진 claim을 해제합니다. (이미 다른 Worker에게 넘어간 로그는 건드리지 않음)
+func (r *BufferRepoImpl) ReleaseClaims(ctx context.Context, workerID string, logIDs []int64) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(logIDs)
+	query := `
+	UPDATE Buffer_Log
+	SET claimed_by = NULL, lease_expires_at = NULL
+	WHERE claimed_by = ? AND log_id IN (` + placeholders + `)`
+
+	if _, err := r.DB.ExecContext(ctx, query, append([]any{workerID}, args...)...); err != nil {
+		return fmt.Errorf("failed to release claimed logs: %w", err)
+	}
+	return nil
+}
+
+// GetCommittedBefore: log_updated_at이 before 이전(같은 초 포함)인 반영 완료 로그를 오래된 순으로 가져옵니다.
+func (r *BufferRepoImpl) GetCommittedBefore(ctx context.Context, before time.Time, limit int) ([]model.BufferLog, error) {
+	query := `
+	SELECT ` + bufferLogColumns + `
+	FROM Buffer_Log
+	WHERE is_committed = 1 AND log_updated_at <= ?
+	ORDER BY log_id
+	LIMIT ?`
+
+	rows, err := r.DB.QueryContext(ctx, query, before.UTC().Format(sqliteTimeFormat), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query committed logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []model.BufferLog
+	for rows.Next() {
+		log, err := scanBufferLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return logs, nil
+}
+
+// DeleteCommitted: 반영 완료된 로그를 지웁니다. (is_committed = 0인 로그는 ID가 섞여 있어도 남김)
+func (r *BufferRepoImpl) DeleteCommitted(ctx context.Context, logIDs []int64) (int64, error) {
+	if len(logIDs) == 0 {
+		return 0, nil
+	}
+
+	placeholders, args := inClause(logIDs)
+	query := `
+	DELETE FROM Buffer_Log
+	WHERE is_committed = 1 AND log_id IN (` + placeholders + `)`
+
+	result, err := r.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete committed logs: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	return deleted, nil
+}
+
+// PurgeExpiredIdempotencyKeys: expires_at이 지난 멱등성 키를 지웁니다. (지워진 키로 다시 요청하면 새 로그가 됨)
+func (r *BufferRepoImpl) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `
+	DELETE FROM Buffer_Idempotency_Key
+	WHERE expires_at <= strftime('%Y-%m-%d %H:%M:%S', 'now')`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	return purged, nil
+}
+
+// inClause: IN (...) 절에 쓸 자리표시자와 인자 목록을 만듭니다.
+func inClause(ids []int64) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
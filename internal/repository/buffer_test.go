@@ -3,40 +3,26 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
-
+	"restaurant_db/internal/db"
 	"restaurant_db/internal/model"
 	"restaurant_db/internal/repository"
 )
 
-// Buffer_Log 에러는 아직 db가 만들어지지 않아서 생기는 오류, 테스트 코드에서 db를 만드니까 상관 없음
-// 추후 db.go 를 추가해 db생성 코드를 짤 예정
-
-// setupTestDB는 TDD를 위해 테스트용 SQLite 인메모리 DB를 설정하고 초기화합니다.
+// setupTestDB는 TDD를 위해 테스트마다 분리된 SQLite 인메모리 DB를 설정하고 초기화합니다.
 func setupTestDB(t *testing.T) *sql.DB {
-	// 1. SQLite 인메모리 DB 연결 (테스트 후 자동 삭제됨)
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("could not open database connection: %v", err)
-	}
-
-	// 2. 스키마 로드 및 실행 (DDL 실행)
-	// 실제 프로젝트에서는 internal/db/db.go의 InitDB 함수를 사용합니다.
-	schemaSQL, err := os.ReadFile("../db/schema.sql")
-	if err != nil {
-		t.Fatalf("could not read schema file: %v", err)
-	}
-	_, err = db.Exec(string(schemaSQL))
+	// internal/db의 InitDB가 내장된 schema.sql과 마이그레이션을 적용합니다. (테스트 후 Close 시 자동 삭제됨)
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
 	if err != nil {
-		t.Fatalf("could not execute schema: %v", err)
+		t.Fatalf("could not initialize database: %v", err)
 	}
 
-	return db
+	return conn
 }
 
 // TestAddLog는 BufferRepository.AddLog 함수의 TDD 테스트 케이스입니다.
@@ -172,3 +158,220 @@ func TestBatchCommitLifecycle(t *testing.T) {
 		t.Errorf("Expected remaining log ID to be 4, got %d", remainingLogs[0].LogID)
 	}
 }
+
+// TestClaimBatchLease: 두 Worker의 claim이 겹치지 않고, 만료된 lease는 다시 가져갈 수 있으며,
+// lease를 빼앗긴 Worker는 커밋할 수 없는지 확인합니다.
+func TestClaimBatchLease(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewBufferRepository(db)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		insertMockLog(t, db, model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", Payload: "{}", TargetRecordID: int64(i)})
+	}
+
+	// 1. 두 Worker가 차례로 가져가면 서로 다른 로그를 받음
+	first, err := repo.ClaimBatch(ctx, "worker-a", time.Minute, 3)
+	if err != nil {
+		t.Fatalf("ClaimBatch failed: %v", err)
+	}
+	second, err := repo.ClaimBatch(ctx, "worker-b", time.Minute, 3)
+	if err != nil {
+		t.Fatalf("ClaimBatch failed: %v", err)
+	}
+	if len(first) != 3 || len(second) != 2 || first[0].LogID != 1 || second[0].LogID != 4 {
+		t.Fatalf("Expected disjoint claims [1..3] and [4..5], got %d and %d logs", len(first), len(second))
+	}
+	if first[0].ClaimedBy != "worker-a" || first[0].LeaseExpiresAt.IsZero() {
+		t.Errorf("Expected claim owner and lease expiry to be returned, got %+v", first[0])
+	}
+
+	// 2. worker-a가 죽어 lease가 만료되면 worker-b가 다시 가져감
+	if _, err := db.Exec("UPDATE Buffer_Log SET lease_expires_at = '2000-01-01 00:00:00' WHERE claimed_by = 'worker-a'"); err != nil {
+		t.Fatalf("Failed to expire lease: %v", err)
+	}
+	reclaimed, err := repo.ClaimBatch(ctx, "worker-b", time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimBatch failed: %v", err)
+	}
+	if len(reclaimed) != 3 {
+		t.Fatalf("Expected 3 expired logs to be reclaimed, got %d", len(reclaimed))
+	}
+
+	// 3. lease를 빼앗긴 worker-a의 커밋은 거부되고, worker-b의 커밋은 성공
+	if err := repo.CommitClaimed(ctx, "worker-a", []int64{1, 2, 3}); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost for stale worker, got %v", err)
+	}
+	if err := repo.CommitClaimed(ctx, "worker-b", []int64{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("CommitClaimed failed: %v", err)
+	}
+	if pending, _ := repo.GetPendingLogs(ctx, 10); len(pending) != 0 {
+		t.Errorf("Expected all logs committed, got %d pending", len(pending))
+	}
+}
+
+// TestClaimBatchKeepsRecordOrder: 같은 레코드의 앞선 로그가 다른 Worker에게 있거나 재시도 대기 중이면
+// 뒤의 로그는 가져가지 않는지 확인합니다.
+func TestClaimBatchKeepsRecordOrder(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewBufferRepository(db)
+	ctx := context.Background()
+
+	// 1. Given: User 1, User 2, User 1 순서의 로그 (log_id 1, 2, 3)
+	for _, userID := range []int64{1, 2, 1} {
+		insertMockLog(t, db, model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", Payload: "{}", TargetRecordID: userID})
+	}
+
+	// 2. When: worker-a가 log 1을 가져간 뒤 worker-b가 나머지를 요청
+	if first, _ := repo.ClaimBatch(ctx, "worker-a", time.Minute, 1); len(first) != 1 || first[0].LogID != 1 {
+		t.Fatalf("Expected worker-a to claim log 1, got %+v", first)
+	}
+	second, err := repo.ClaimBatch(ctx, "worker-b", time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimBatch failed: %v", err)
+	}
+
+	// 3. Then: User 1의 뒤 로그(3)는 건너뛰고 User 2의 로그만 받음
+	if len(second) != 1 || second[0].LogID != 2 {
+		t.Fatalf("Expected worker-b to claim only log 2, got %+v", second)
+	}
+
+	// 4. log 1이 실패해 재시도 대기에 들어가도 log 3은 여전히 막혀 있음
+	if err := repo.RecordFailure(ctx, 1, "boom", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if blocked, _ := repo.ClaimBatch(ctx, "worker-b", time.Minute, 10); len(blocked) != 0 {
+		t.Fatalf("Expected log 3 to wait behind retrying log 1, got %+v", blocked)
+	}
+
+	// 5. log 1이 반영되면 log 3을 가져갈 수 있음
+	if err := repo.UpdateCommitted(ctx, []int64{1}); err != nil {
+		t.Fatalf("UpdateCommitted failed: %v", err)
+	}
+	if third, _ := repo.ClaimBatch(ctx, "worker-b", time.Minute, 10); len(third) != 1 || third[0].LogID != 3 {
+		t.Errorf("Expected log 3 after log 1 committed, got %+v", third)
+	}
+}
+
+// TestAddLogIdempotencyKey: 같은 멱등성 키로 다시 추가하면 새 행 없이 처음 로그 ID를 돌려주고,
+// 보관 기간이 지난 키는 새 요청으로 취급되는지 확인합니다.
+func TestAddLogIdempotencyKey(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	repo := repository.NewBufferRepository(db)
+	newLog := func(key string) model.BufferLog {
+		return model.BufferLog{TransactionType: "INSERT", TargetTable: "Review", Payload: `{"rating": 4}`, IdempotencyKey: key}
+	}
+
+	// 1. Given: 키를 가진 첫 요청
+	first := newLog("review-retry-1")
+	if err := repo.AddLog(ctx, &first); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 2. When: 타임아웃 후 같은 키로 재시도, 다른 키로 새 요청
+	retry := newLog("review-retry-1")
+	if err := repo.AddLog(ctx, &retry); err != nil {
+		t.Fatalf("AddLog retry failed: %v", err)
+	}
+	other := newLog("review-retry-2")
+	if err := repo.AddLog(ctx, &other); err != nil {
+		t.Fatalf("AddLog other failed: %v", err)
+	}
+
+	// 3. Then: 재시도는 처음 로그 ID를 받고 행은 2개만 생김
+	if retry.LogID != first.LogID {
+		t.Errorf("Expected retry to return log %d, got %d", first.LogID, retry.LogID)
+	}
+	if other.LogID == first.LogID {
+		t.Errorf("Expected a different key to create a new log")
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM Buffer_Log").Scan(&count); err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 logs, got %d", count)
+	}
+
+	// 보관 기간이 0이면 키가 바로 만료되어 같은 키도 새 로그가 됨
+	expiring := repository.NewBufferRepository(db, repository.WithIdempotencyTTL(0))
+	a, b := newLog("expired-key"), newLog("expired-key")
+	if err := expiring.AddLog(ctx, &a); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+	if err := expiring.AddLog(ctx, &b); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+	if a.LogID == b.LogID {
+		t.Errorf("Expected expired key to create a new log, got %d twice", a.LogID)
+	}
+}
+
+// TestAddLogIdempotencyKeyConcurrent: 같은 키의 재시도가 앞 요청의 트랜잭션이 끝나기 전에 들어와도
+// SQLITE_BUSY 없이 기다렸다가 처음 로그 ID를 받고, 로그는 하나만 생기는지 확인합니다.
+func TestAddLogIdempotencyKeyConcurrent(t *testing.T) {
+	// 두 연결이 겹쳐 쓰므로 디스크 DB 사용 (IMMEDIATE 없이 DEFERRED 트랜잭션이어도 키 쓰기에서 기다려야 함)
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "buffer.db") + "?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+	newLog := func() model.BufferLog {
+		return model.BufferLog{TransactionType: "INSERT", TargetTable: "Review", Payload: `{"rating": 4}`, IdempotencyKey: "concurrent-retry"}
+	}
+
+	// 1. Given: 첫 요청이 호출자의 트랜잭션 안에서 로그를 추가하고 아직 커밋하지 않음
+	firstTx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer firstTx.Rollback()
+	retryTx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer retryTx.Rollback()
+
+	first := newLog()
+	if err := repository.NewBufferRepository(firstTx).AddLog(ctx, &first); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 2. When: 같은 키의 재시도가 다른 트랜잭션에서 들어온 뒤 첫 요청이 커밋
+	retry := newLog()
+	done := make(chan error, 1)
+	go func() {
+		if err := repository.NewBufferRepository(retryTx).AddLog(ctx, &retry); err != nil {
+			done <- err
+			return
+		}
+		done <- retryTx.Commit()
+	}()
+	time.Sleep(50 * time.Millisecond) // 재시도가 첫 요청의 쓰기 잠금을 기다리는 중
+	if err := firstTx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// 3. Then: 재시도는 오류 없이 처음 로그 ID를 받고, 행은 하나만 생김
+	if err := <-done; err != nil {
+		t.Fatalf("AddLog retry failed: %v", err)
+	}
+	if retry.LogID != first.LogID {
+		t.Errorf("Expected retry to return log %d, got %d", first.LogID, retry.LogID)
+	}
+	var count int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Buffer_Log").Scan(&count); err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 log, got %d", count)
+	}
+}
@@ -5,21 +5,35 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"restaurant_db/internal/logging"
 	"restaurant_db/internal/model"
 )
 
 type CacheRepository interface {
 	FindCacheByID(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error)
+	// Upsert: 집계 결과를 캐시에 넣거나 갱신합니다. 기존 행의 cache_score는 유지됩니다.
+	Upsert(ctx context.Context, cache *model.CacheMetadata) error
+	// Delete: 캐시에서 식당을 내립니다.
+	Delete(ctx context.Context, restaurantID int64) error
+
+	// 아래는 cache_score 기반 캐시 정책(cache.Policy)이 사용하는 메소드
+	IncrementScore(ctx context.Context, restaurantID int64, delta float64) error
+	DecayScores(ctx context.Context, factor float64) error
+	EvictBelow(ctx context.Context, threshold float64) (int64, error)
+	EvictLowest(ctx context.Context, n int64) (int64, error)
+	Count(ctx context.Context) (int64, error)
 }
 
 type CacheRepoImpl struct {
-	DB *sql.DB
+	DB     DBTX
+	Logger *slog.Logger // 갱신/삭제/퇴출 이벤트
 }
 
-func NewCacheRepository(db *sql.DB) CacheRepository {
-	return &CacheRepoImpl{DB: db}
+func NewCacheRepository(db DBTX, opts ...Option) CacheRepository {
+	return &CacheRepoImpl{DB: db, Logger: componentLogger(opts, "cache_repository")}
 }
 
 // FindCacheByID: 캐시 테이블에서 데이터를 조회합니다.
@@ -54,7 +68,6 @@ func (r *CacheRepoImpl) FindCacheByID(ctx context.Context, restaurantID int64) (
 		return nil, fmt.Errorf("failed to find cache by ID: %w", err)
 	}
 
-	const sqliteTimeFormat = "2006-01-02 15:04:05"
 	cache.LastCacheUpdatedAt, err = time.Parse(sqliteTimeFormat, lastUpdatedStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse cache updated_at: %w", err)
@@ -62,3 +75,122 @@ func (r *CacheRepoImpl) FindCacheByID(ctx context.Context, restaurantID int64) (
 
 	return cache, nil
 }
+
+// Upsert: 캐시 행이 없으면 새로 만들고, 있으면 집계 필드와 last_cache_updated_at만 갱신합니다.
+// cache_score는 캐시 정책이 관리하므로 새로 들어오는 행에만 cache.CacheScore를 사용합니다.
+func (r *CacheRepoImpl) Upsert(ctx context.Context, cache *model.CacheMetadata) error {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	query := `
+		INSERT INTO Cache_Metadata (
+			restaurant_id, location_ref_id, category_ref_id, weighted_rating,
+			total_weighted_reviews, cache_score, last_cache_updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(restaurant_id) DO UPDATE SET
+			location_ref_id = excluded.location_ref_id,
+			category_ref_id = excluded.category_ref_id,
+			weighted_rating = excluded.weighted_rating,
+			total_weighted_reviews = excluded.total_weighted_reviews,
+			last_cache_updated_at = excluded.last_cache_updated_at`
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		cache.RestaurantID,
+		cache.LocationRefID,
+		cache.CategoryRefID,
+		cache.WeightedRating,
+		cache.TotalWeightedReviews,
+		cache.CacheScore,
+		now.Format(sqliteTimeFormat),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert cache (ID: %d): %w", cache.RestaurantID, err)
+	}
+
+	cache.LastCacheUpdatedAt = now
+	r.Logger.Debug("cache row upserted", logging.RestaurantID(cache.RestaurantID))
+	return nil
+}
+
+// Delete: Cache_Metadata에서 식당 행을 제거합니다. (없으면 아무 일도 하지 않음)
+func (r *CacheRepoImpl) Delete(ctx context.Context, restaurantID int64) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM Cache_Metadata WHERE restaurant_id = ?`, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cache (ID: %d): %w", restaurantID, err)
+	}
+	r.Logger.Debug("cache row deleted", logging.RestaurantID(restaurantID))
+	return nil
+}
+
+// IncrementScore: 조회된 식당의 cache_score를 delta만큼 올립니다. (캐시에 없으면 아무 일도 하지 않음)
+func (r *CacheRepoImpl) IncrementScore(ctx context.Context, restaurantID int64, delta float64) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE Cache_Metadata SET cache_score = cache_score + ? WHERE restaurant_id = ?`,
+		delta, restaurantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to increment cache score (ID: %d): %w", restaurantID, err)
+	}
+	return nil
+}
+
+// DecayScores: 모든 캐시 행의 cache_score에 factor(0 ~ 1)를 곱해 오래된 인기도를 줄입니다.
+func (r *CacheRepoImpl) DecayScores(ctx context.Context, factor float64) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE Cache_Metadata SET cache_score = cache_score * ?`, factor)
+	if err != nil {
+		return fmt.Errorf("failed to decay cache scores: %w", err)
+	}
+	return nil
+}
+
+// EvictBelow: cache_score가 threshold 미만인 행을 캐시에서 내리고, 내린 행 수를 반환합니다.
+func (r *CacheRepoImpl) EvictBelow(ctx context.Context, threshold float64) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM Cache_Metadata WHERE cache_score < ?`, threshold)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evict cache rows below %.2f: %w", threshold, err)
+	}
+	return r.evicted(result, "threshold")
+}
+
+// EvictLowest: cache_score가 가장 낮은(동점이면 오래 갱신되지 않은) n개 행을 내립니다.
+func (r *CacheRepoImpl) EvictLowest(ctx context.Context, n int64) (int64, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+	query := `
+		DELETE FROM Cache_Metadata
+		WHERE restaurant_id IN (
+			SELECT restaurant_id
+			FROM Cache_Metadata
+			ORDER BY cache_score ASC, last_cache_updated_at ASC
+			LIMIT ?
+		)`
+
+	result, err := r.DB.ExecContext(ctx, query, n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evict %d lowest cache rows: %w", n, err)
+	}
+	return r.evicted(result, "lowest")
+}
+
+// evicted: 퇴출된 행 수를 읽고 기록합니다. (reason은 퇴출 기준)
+func (r *CacheRepoImpl) evicted(result sql.Result, reason string) (int64, error) {
+	evicted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read evicted rows: %w", err)
+	}
+	if evicted > 0 {
+		r.Logger.Debug("cache rows evicted", slog.Int64("evicted", evicted), slog.String("reason", reason))
+	}
+	return evicted, nil
+}
+
+// Count: 현재 캐시 테이블의 행 수
+func (r *CacheRepoImpl) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM Cache_Metadata`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cache rows: %w", err)
+	}
+	return count, nil
+}
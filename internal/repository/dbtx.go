@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"restaurant_db/internal/logging"
+)
+
+// sqliteTimeFormat: 스키마 기본값 strftime('%Y-%m-%d %H:%M:%S', 'now')과 같은 시각 문자열 형식 (UTC, 초 단위)
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// DBTX: Repository가 쿼리를 실행하는 대상입니다. *sql.DB와 *sql.Tx 모두 만족하므로,
+// 같은 Repository를 트랜잭션 안에서 만들면 그 안의 모든 쓰기가 하나의 트랜잭션으로 묶입니다.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Repositories: 같은 실행 대상(DB 또는 트랜잭션)을 공유하는 Repository 묶음
+type Repositories struct {
+	Buffer     BufferRepository
+	User       UserRepository
+	Restaurant RestaurantRepository
+	Review     ReviewRepository
+	Analysis   AnalysisLogRepository
+	Cache      CacheRepository
+	DeadLetter DeadLetterRepository
+}
+
+// NewRepositories: db(또는 tx) 하나로 모든 Repository를 만듭니다. opts의 Logger는 Buffer와 DeadLetter에도 쓰입니다.
+func NewRepositories(db DBTX, opts ...Option) *Repositories {
+	logger := resolveOptions(opts).logger
+	return &Repositories{
+		Buffer:     NewBufferRepository(db, WithLogger(logger)),
+		User:       NewUserRepository(db, opts...),
+		Restaurant: NewRestaurantRepository(db, opts...),
+		Review:     NewReviewRepository(db, opts...),
+		Analysis:   NewAnalysisLogRepository(db, opts...),
+		Cache:      NewCacheRepository(db, opts...),
+		DeadLetter: NewDeadLetterRepository(db, WithLogger(logger)),
+	}
+}
+
+// Option: Restaurant, User, Review, Analysis, Cache Repository 생성자에 넘기는 선택 설정
+// (Buffer와 DeadLetter는 BufferOption을 받음)
+type Option func(*options)
+
+type options struct {
+	logger *slog.Logger
+}
+
+// WithRepositoryLogger: 쓰기 이벤트를 logger로 기록합니다. (기본값은 slog.Default, 트랜잭션 안에서는 커밋 전에 기록됨)
+func WithRepositoryLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+func resolveOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// componentLogger: opts의 Logger(없으면 slog.Default)에 component 필드를 붙입니다.
+func componentLogger(opts []Option, name string) *slog.Logger {
+	return logging.Component(resolveOptions(opts).logger, name)
+}
+
+// inTx: 여러 문장을 원자적으로 실행합니다. db가 *sql.DB이면 트랜잭션을 새로 열고,
+// 이미 트랜잭션(*sql.Tx 등)이면 바깥 트랜잭션에 그대로 참여합니다.
+func inTx(ctx context.Context, db DBTX, fn func(DBTX) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
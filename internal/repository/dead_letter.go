@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"restaurant_db/internal/model"
+)
+
+// ErrNewerLogsExist: 재투입하려는 Dead Letter보다 나중에 추가된 같은 대상 행의 로그가 있을 때 반환됩니다.
+var ErrNewerLogsExist = errors.New("newer buffer logs exist for the same record")
+
+// DeadLetterRepository: 재시도를 포기한 버퍼 로그(Buffer_Dead_Letter)를 운영자가 확인/수정/재투입할 때 사용합니다.
+type DeadLetterRepository interface {
+	// List: 최근에 실패한 순으로 Dead Letter 목록을 조회합니다.
+	List(ctx context.Context, limit, offset int) ([]model.DeadLetter, error)
+	FindByID(ctx context.Context, deadLetterID int64) (*model.DeadLetter, error)
+	// Update: 잘못된 페이로드 등을 고칠 수 있도록 로그 내용(트랜잭션 종류, 대상, 페이로드)을 수정합니다. (검증기가 있으면 통과한 내용만 저장)
+	Update(ctx context.Context, deadLetter *model.DeadLetter) error
+	// Requeue: Dead Letter를 새 Buffer_Log로 다시 넣고(시도 횟수 0) 새 log_id를 반환합니다.
+	// 같은 대상 행에 원래 로그보다 나중에 추가된 로그가 있으면 ErrNewerLogsExist로 거절합니다.
+	Requeue(ctx context.Context, deadLetterID int64) (int64, error)
+	// Count: 처리를 기다리는 Dead Letter 수
+	Count(ctx context.Context) (int64, error)
+}
+
+// DeadLetterRepoImpl은 DeadLetterRepository 인터페이스를 구현합니다.
+type DeadLetterRepoImpl struct {
+	DB DBTX
+	// Buffer: 재투입할 때 로그를 검증하고 Worker에 알리는 버퍼 설정 (DB 대신 Requeue의 트랜잭션에 씀)
+	Buffer *BufferRepoImpl
+}
+
+// NewDeadLetterRepository: opts는 재투입 경로의 BufferRepository 설정입니다. (WithValidator, WithNotifier 등 AddLog와 같은 검증/알림)
+func NewDeadLetterRepository(db DBTX, opts ...BufferOption) DeadLetterRepository {
+	return &DeadLetterRepoImpl{DB: db, Buffer: newBufferRepo(db, opts...)}
+}
+
+const deadLetterColumns = `
+	dead_letter_id, original_log_id, transaction_type, target_table, payload,
+	payload_version, target_record_id, attempt_count, last_error, failed_at`
+
+// List: failed_at 내림차순(동률이면 ID 내림차순)으로 페이지 단위 조회합니다.
+func (r *DeadLetterRepoImpl) List(ctx context.Context, limit, offset int) ([]model.DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + `
+		FROM Buffer_Dead_Letter
+		ORDER BY failed_at DESC, dead_letter_id DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []model.DeadLetter
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		deadLetters = append(deadLetters, *deadLetter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return deadLetters, nil
+}
+
+func (r *DeadLetterRepoImpl) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM Buffer_Dead_Letter`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return count, nil
+}
+
+// FindByID: Dead Letter 하나를 조회합니다. (없으면 nil)
+func (r *DeadLetterRepoImpl) FindByID(ctx context.Context, deadLetterID int64) (*model.DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + `
+		FROM Buffer_Dead_Letter
+		WHERE dead_letter_id = ?`
+
+	deadLetter, err := scanDeadLetter(r.DB.QueryRowContext(ctx, query, deadLetterID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find dead letter by ID: %w", err)
+	}
+	return deadLetter, nil
+}
+
+// Update: 로그 내용만 수정합니다. (시도 횟수와 오류 기록은 그대로 남김)
+// 검증기가 거절한 내용은 ErrInvalidLog로 반환하고 저장하지 않습니다.
+func (r *DeadLetterRepoImpl) Update(ctx context.Context, deadLetter *model.DeadLetter) error {
+	if r.Buffer.Validator != nil {
+		log := requeueLog(deadLetter)
+		if err := r.Buffer.Validator.Validate(&log); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidLog, err)
+		}
+		deadLetter.PayloadVersion = log.PayloadVersion
+	}
+
+	query := `
+		UPDATE Buffer_Dead_Letter
+		SET
+			transaction_type = ?,
+			target_table = ?,
+			payload = ?,
+			payload_version = ?,
+			target_record_id = ?
+		WHERE dead_letter_id = ?`
+
+	result, err := r.DB.ExecContext(
+		ctx,
+		query,
+		deadLetter.TransactionType,
+		deadLetter.TargetTable,
+		deadLetter.Payload,
+		deadLetter.PayloadVersion,
+		deadLetter.TargetRecordID,
+		deadLetter.DeadLetterID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter (ID: %d): %w", deadLetter.DeadLetterID, err)
+	}
+
+	return requireAffected(result, deadLetter.DeadLetterID)
+}
+
+// Requeue: Dead Letter를 AddLog로 Buffer_Log에 다시 넣고 Dead Letter 행을 지웁니다. (하나의 트랜잭션으로 실행, 알림은 커밋 후)
+// 새 로그는 그 사이에 쌓인 로그들 뒤에 반영되므로, 같은 대상 행에 원래 로그보다 나중에 추가된 로그가 남아 있으면
+// 예전 내용이 새 상태를 덮어쓰지 않도록 거절합니다. (압축으로 이미 지워진 로그는 확인할 수 없음)
+func (r *DeadLetterRepoImpl) Requeue(ctx context.Context, deadLetterID int64) (int64, error) {
+	var log model.BufferLog
+
+	err := inTx(ctx, r.DB, func(db DBTX) error {
+		deadLetter, err := (&DeadLetterRepoImpl{DB: db}).FindByID(ctx, deadLetterID)
+		if err != nil {
+			return err
+		}
+		if deadLetter == nil {
+			return fmt.Errorf("dead letter ID %d: %w", deadLetterID, ErrNotFound)
+		}
+
+		// 대상 행이 정해진 로그만 순서가 의미 있음 (INSERT는 target_record_id가 0)
+		if deadLetter.TargetRecordID != 0 {
+			var newer int64
+			err := db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM Buffer_Log
+			WHERE target_table = ? AND target_record_id = ? AND log_id > ?`,
+				deadLetter.TargetTable, deadLetter.TargetRecordID, deadLetter.OriginalLogID).Scan(&newer)
+			if err != nil {
+				return fmt.Errorf("failed to check newer logs for dead letter %d: %w", deadLetterID, err)
+			}
+			if newer > 0 {
+				return fmt.Errorf("dead letter %d (%s %d, %d newer): %w",
+					deadLetterID, deadLetter.TargetTable, deadLetter.TargetRecordID, newer, ErrNewerLogsExist)
+			}
+		}
+
+		buffer := *r.Buffer
+		buffer.DB = db
+		buffer.Notifier = nil
+		log = requeueLog(deadLetter)
+		if err := buffer.AddLog(ctx, &log); err != nil {
+			return fmt.Errorf("failed to requeue dead letter %d: %w", deadLetterID, err)
+		}
+
+		if _, err := db.ExecContext(ctx, `DELETE FROM Buffer_Dead_Letter WHERE dead_letter_id = ?`, deadLetterID); err != nil {
+			return fmt.Errorf("failed to remove requeued dead letter %d: %w", deadLetterID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if r.Buffer.Notifier != nil {
+		r.Buffer.Notifier.LogAdded(log)
+	}
+	return log.LogID, nil
+}
+
+// requeueLog: Dead Letter의 로그 내용으로 새 Buffer_Log를 만듭니다. (시도 횟수와 오류 기록은 넘기지 않음)
+func requeueLog(deadLetter *model.DeadLetter) model.BufferLog {
+	return model.BufferLog{
+		TransactionType: deadLetter.TransactionType,
+		TargetTable:     deadLetter.TargetTable,
+		Payload:         deadLetter.Payload,
+		PayloadVersion:  deadLetter.PayloadVersion,
+		TargetRecordID:  deadLetter.TargetRecordID,
+	}
+}
+
+// scanDeadLetter: deadLetterColumns 순서대로 한 행을 읽고 시간 필드를 파싱합니다.
+func scanDeadLetter(row rowScanner) (*model.DeadLetter, error) {
+	deadLetter := &model.DeadLetter{}
+	var targetRecordID sql.NullInt64
+	var failedAtStr string
+
+	err := row.Scan(
+		&deadLetter.DeadLetterID,
+		&deadLetter.OriginalLogID,
+		&deadLetter.TransactionType,
+		&deadLetter.TargetTable,
+		&deadLetter.Payload,
+		&deadLetter.PayloadVersion,
+		&targetRecordID,
+		&deadLetter.AttemptCount,
+		&deadLetter.LastError,
+		&failedAtStr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadLetter.FailedAt, err = time.Parse(sqliteTimeFormat, failedAtStr); err != nil {
+		return nil, fmt.Errorf("failed to parse dead letter failed_at: %w", err)
+	}
+	deadLetter.TargetRecordID = targetRecordID.Int64
+
+	return deadLetter, nil
+}
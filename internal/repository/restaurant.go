@@ -3,48 +3,228 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"restaurant_db/internal/logging"
 	"restaurant_db/internal/model"
 )
 
+// ErrNotFound: 수정/삭제 대상 레코드가 존재하지 않을 때 반환됩니다.
+var ErrNotFound = errors.New("record not found")
+
 // RestaurantRepository: Restaurant 테이블에 접근합니다.
 type RestaurantRepository interface {
+	Create(ctx context.Context, restaurant *model.Restaurant) error
 	// FindByID: 캐시 미스 시 릴레이션에 직접 접근하여 식당 정보를 조회합니다.
 	FindByID(ctx context.Context, restaurantID int64) (*model.Restaurant, error)
+	Update(ctx context.Context, restaurant *model.Restaurant) error
+	Delete(ctx context.Context, restaurantID int64) error
+	List(ctx context.Context, limit, offset int) ([]model.Restaurant, error)
+	// Touch: 조회된 식당의 last_accessed_at을 현재 시각으로 갱신합니다. (캐시 정책이 모아 둔 조회를 반영할 때 호출)
+	Touch(ctx context.Context, restaurantID int64) error
 }
 
 // RestaurantRepoImpl은 RestaurantRepository 인터페이스를 구현합니다.
 type RestaurantRepoImpl struct {
-	DB *sql.DB
+	DB     DBTX
+	Logger *slog.Logger // 생성/수정/삭제 이벤트
+}
+
+func NewRestaurantRepository(db DBTX, opts ...Option) RestaurantRepository {
+	return &RestaurantRepoImpl{DB: db, Logger: componentLogger(opts, "restaurant_repository")}
 }
 
-func NewRestaurantRepository(db *sql.DB) RestaurantRepository {
-	return &RestaurantRepoImpl{DB: db}
+const restaurantColumns = `
+	restaurant_id, owner, restaurant_name, restaurant_address,
+	location_ref_id, category_ref_id,
+	created_at, last_modified_at, last_accessed_at`
+
+// Create: 새로운 식당을 Restaurant 테이블에 추가하고 ID를 할당합니다.
+func (r *RestaurantRepoImpl) Create(ctx context.Context, restaurant *model.Restaurant) error {
+	query := `
+		INSERT INTO Restaurant (
+			owner, restaurant_name, restaurant_address, location_ref_id, category_ref_id
+		) VALUES (?, ?, ?, ?, ?)` // 시간 메타데이터는 DDL의 기본값(DEFAULT)을 사용
+
+	result, err := r.DB.ExecContext(
+		ctx,
+		query,
+		restaurant.Owner,
+		restaurant.RestaurantName,
+		restaurant.RestaurantAddress,
+		restaurant.LocationRefID,
+		restaurant.CategoryRefID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create restaurant: %w", err)
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read inserted restaurant ID: %w", err)
+	}
+	restaurant.RestaurantID = lastID
+	r.Logger.Debug("restaurant created", logging.RestaurantID(restaurant.RestaurantID))
+	return nil
 }
 
-// FindByID: 캐시 미스 시 릴레이션에 직접 접근하여 식당 정보를 조회합니다. (느린 I/O 시뮬레이션)
+// FindByID: 캐시 미스 시 릴레이션에 직접 접근하여 식당 정보를 조회합니다.
 func (r *RestaurantRepoImpl) FindByID(ctx context.Context, restaurantID int64) (*model.Restaurant, error) {
-	// 성능 분석을 위해 릴레이션 접근 시간을 시뮬레이션합니다.
-	time.Sleep(10 * time.Millisecond)
+	query := `SELECT ` + restaurantColumns + `
+		FROM Restaurant
+		WHERE restaurant_id = ?`
 
-	restaurant := &model.Restaurant{}
+	row := r.DB.QueryRowContext(ctx, query, restaurantID)
+
+	restaurant, err := scanRestaurant(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // 식당 없음
+		}
+		return nil, fmt.Errorf("failed to find restaurant by ID: %w", err)
+	}
 
-	// 실제 쿼리 로직은 다음과 같지만, 최소 구현을 위해 간단히 반환합니다.
-	/*
-		query := `
-			SELECT restaurant_id, restaurant_name, location_ref_id, category_ref_id, created_at
-			FROM Restaurant
-			WHERE restaurant_id = ?`
+	return restaurant, nil
+}
+
+// Update: 식당 정보를 수정하고 last_modified_at을 갱신합니다.
+func (r *RestaurantRepoImpl) Update(ctx context.Context, restaurant *model.Restaurant) error {
+	query := `
+		UPDATE Restaurant
+		SET
+			owner = ?,
+			restaurant_name = ?,
+			restaurant_address = ?,
+			location_ref_id = ?,
+			category_ref_id = ?,
+			last_modified_at = strftime('%Y-%m-%d %H:%M:%S', 'now')
+		WHERE restaurant_id = ?`
+
+	result, err := r.DB.ExecContext(
+		ctx,
+		query,
+		restaurant.Owner,
+		restaurant.RestaurantName,
+		restaurant.RestaurantAddress,
+		restaurant.LocationRefID,
+		restaurant.CategoryRefID,
+		restaurant.RestaurantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update restaurant (ID: %d): %w", restaurant.RestaurantID, err)
+	}
+	if err := requireAffected(result, restaurant.RestaurantID); err != nil {
+		return err
+	}
 
-		row := r.DB.QueryRowContext(ctx, query, restaurantID)
-		// ... (Scan 및 시간 파싱 로직)
-	*/
+	r.Logger.Debug("restaurant updated", logging.RestaurantID(restaurant.RestaurantID))
+	return nil
+}
+
+// Delete: 식당을 Restaurant 테이블에서 삭제합니다.
+func (r *RestaurantRepoImpl) Delete(ctx context.Context, restaurantID int64) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM Restaurant WHERE restaurant_id = ?`, restaurantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete restaurant (ID: %d): %w", restaurantID, err)
+	}
+	if err := requireAffected(result, restaurantID); err != nil {
+		return err
+	}
+
+	r.Logger.Info("restaurant deleted", logging.RestaurantID(restaurantID))
+	return nil
+}
 
-	// 임시 데이터 반환
-	restaurant.RestaurantID = restaurantID
-	restaurant.RestaurantName = fmt.Sprintf("식당_%d", restaurantID)
+// List: restaurant_id 순으로 식당 목록을 페이지 단위로 조회합니다.
+func (r *RestaurantRepoImpl) List(ctx context.Context, limit, offset int) ([]model.Restaurant, error) {
+	query := `SELECT ` + restaurantColumns + `
+		FROM Restaurant
+		ORDER BY restaurant_id
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query restaurants: %w", err)
+	}
+	defer rows.Close()
+
+	var restaurants []model.Restaurant
+	for rows.Next() {
+		restaurant, err := scanRestaurant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan restaurant: %w", err)
+		}
+		restaurants = append(restaurants, *restaurant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return restaurants, nil
+}
+
+// Touch: last_accessed_at만 현재 시각으로 갱신합니다. (없는 식당이면 아무 일도 하지 않음)
+func (r *RestaurantRepoImpl) Touch(ctx context.Context, restaurantID int64) error {
+	query := `
+		UPDATE Restaurant
+		SET last_accessed_at = strftime('%Y-%m-%d %H:%M:%S', 'now')
+		WHERE restaurant_id = ?`
+
+	if _, err := r.DB.ExecContext(ctx, query, restaurantID); err != nil {
+		return fmt.Errorf("failed to touch restaurant (ID: %d): %w", restaurantID, err)
+	}
+	return nil
+}
+
+// rowScanner: *sql.Row와 *sql.Rows를 같은 방식으로 스캔하기 위한 인터페이스
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRestaurant: restaurantColumns 순서대로 한 행을 읽고 시간 필드를 파싱합니다.
+func scanRestaurant(row rowScanner) (*model.Restaurant, error) {
+	restaurant := &model.Restaurant{}
+	var createdAtStr, lastModifiedStr, lastAccessedStr string
+
+	err := row.Scan(
+		&restaurant.RestaurantID,
+		&restaurant.Owner,
+		&restaurant.RestaurantName,
+		&restaurant.RestaurantAddress,
+		&restaurant.LocationRefID,
+		&restaurant.CategoryRefID,
+		&createdAtStr,
+		&lastModifiedStr,
+		&lastAccessedStr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if restaurant.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAtStr); err != nil {
+		return nil, fmt.Errorf("failed to parse restaurant created_at: %w", err)
+	}
+	if restaurant.LastModifiedAt, err = time.Parse(sqliteTimeFormat, lastModifiedStr); err != nil {
+		return nil, fmt.Errorf("failed to parse restaurant last_modified_at: %w", err)
+	}
+	if restaurant.LastAccessedAt, err = time.Parse(sqliteTimeFormat, lastAccessedStr); err != nil {
+		return nil, fmt.Errorf("failed to parse restaurant last_accessed_at: %w", err)
+	}
 
 	return restaurant, nil
 }
+
+// requireAffected: UPDATE/DELETE가 실제로 한 행 이상에 적용되었는지 확인합니다.
+func requireAffected(result sql.Result, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("record ID %d: %w", id, ErrNotFound)
+	}
+	return nil
+}
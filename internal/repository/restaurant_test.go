@@ -0,0 +1,144 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// --- TDD: TestRestaurantCRUD ---
+func TestRestaurantCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := repository.NewRestaurantRepository(db)
+	ctx := context.Background()
+
+	// 1. Given: 새로운 식당 데이터
+	restaurant := model.Restaurant{
+		Owner:             1,
+		RestaurantName:    "김밥천국",
+		RestaurantAddress: "서울시 노원구 공릉로 232",
+		LocationRefID:     1,
+		CategoryRefID:     2,
+	}
+
+	// 2. When: 식당 생성
+	if err := repo.Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Create restaurant failed: %v", err)
+	}
+	if restaurant.RestaurantID <= 0 {
+		t.Fatalf("Expected RestaurantID to be assigned, got %d", restaurant.RestaurantID)
+	}
+
+	// 3. Then: FindByID로 저장된 컬럼 검증
+	fetched, err := repo.FindByID(ctx, restaurant.RestaurantID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if fetched == nil {
+		t.Fatalf("Expected restaurant to be found, got nil")
+	}
+	if fetched.RestaurantName != "김밥천국" || fetched.RestaurantAddress != "서울시 노원구 공릉로 232" {
+		t.Errorf("Unexpected restaurant fields: %+v", fetched)
+	}
+	if fetched.Owner != 1 || fetched.LocationRefID != 1 || fetched.CategoryRefID != 2 {
+		t.Errorf("Unexpected FK fields: %+v", fetched)
+	}
+	if fetched.CreatedAt.IsZero() || fetched.LastModifiedAt.IsZero() || fetched.LastAccessedAt.IsZero() {
+		t.Errorf("Expected timestamps to be populated, got %+v", fetched)
+	}
+
+	// 4. When/Then: 수정
+	fetched.RestaurantName = "김밥천국 본점"
+	fetched.CategoryRefID = 3
+	if err := repo.Update(ctx, fetched); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, err := repo.FindByID(ctx, restaurant.RestaurantID)
+	if err != nil {
+		t.Fatalf("FindByID after update failed: %v", err)
+	}
+	if updated.RestaurantName != "김밥천국 본점" || updated.CategoryRefID != 3 {
+		t.Errorf("Expected updated fields, got %+v", updated)
+	}
+
+	// 5. When/Then: 삭제 후 조회 시 nil
+	if err := repo.Delete(ctx, restaurant.RestaurantID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	deleted, err := repo.FindByID(ctx, restaurant.RestaurantID)
+	if err != nil {
+		t.Fatalf("FindByID after delete failed: %v", err)
+	}
+	if deleted != nil {
+		t.Errorf("Expected restaurant to be deleted, got %+v", deleted)
+	}
+
+	// 존재하지 않는 레코드 수정/삭제는 ErrNotFound
+	if err := repo.Delete(ctx, restaurant.RestaurantID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound on second delete, got %v", err)
+	}
+}
+
+// TestRestaurantList: LIMIT/OFFSET 페이지 조회 테스트
+func TestRestaurantList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := repository.NewRestaurantRepository(db)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		r := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+		if err := repo.Create(ctx, &r); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	page, err := repo.List(ctx, 2, 2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("Expected 2 restaurants, got %d", len(page))
+	}
+	if page[0].RestaurantID != 3 || page[1].RestaurantID != 4 {
+		t.Errorf("Expected IDs 3,4 got %d,%d", page[0].RestaurantID, page[1].RestaurantID)
+	}
+}
+
+// TestRepositoryLogger: 생성자로 넘긴 logger에 component와 공통 필드가 붙은 쓰기 이벤트가 기록되는지 확인합니다.
+func TestRepositoryLogger(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	logger, err := logging.New(logging.Config{Format: logging.FormatJSON, Level: slog.LevelDebug, Output: &out})
+	if err != nil {
+		t.Fatalf("logging.New failed: %v", err)
+	}
+	repos := repository.NewRepositories(db, repository.WithRepositoryLogger(logger))
+
+	restaurant := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+	if err := repos.Restaurant.Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repos.Restaurant.Delete(ctx, restaurant.RestaurantID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	logs := out.String()
+	for _, want := range []string{`"component":"restaurant_repository"`, `"msg":"restaurant deleted"`, `"restaurant_id":1`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("Expected %s in logs, got %s", want, logs)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/model"
+)
+
+// ReviewRepository: Review 테이블에 접근합니다.
+type ReviewRepository interface {
+	// Create: 리뷰를 실제 Review 테이블에 반영합니다. (Worker가 버퍼 로그를 적용할 때 사용)
+	Create(ctx context.Context, review *model.Review) error
+	FindByID(ctx context.Context, reviewID int64) (*model.Review, error)
+	// FindByRestaurant / FindByUser: 최신순으로 limit/offset 페이지 조회
+	FindByRestaurant(ctx context.Context, restaurantID int64, limit, offset int) ([]model.Review, error)
+	FindByUser(ctx context.Context, userID int64, limit, offset int) ([]model.Review, error)
+	// ConsensusDeviation: 유저 평점과 같은 식당의 다른 리뷰 평균 사이의 평균 절대 편차 (reliability.ConsensusSource)
+	ConsensusDeviation(ctx context.Context, userID int64) (float64, int64, error)
+	// WeightedRating: 식당의 reliability_weight 가중 평균 평점과 가중치가 있는 리뷰 개수
+	WeightedRating(ctx context.Context, restaurantID int64) (float64, int64, error)
+}
+
+type ReviewRepoImpl struct {
+	DB     DBTX
+	Logger *slog.Logger // 생성 이벤트
+}
+
+func NewReviewRepository(db DBTX, opts ...Option) ReviewRepository {
+	return &ReviewRepoImpl{DB: db, Logger: componentLogger(opts, "review_repository")}
+}
+
+const reviewColumns = `
+	review_id, restaurant_ref_id, user_ref_id, rating, review_content,
+	reliability_weight, created_at`
+
+// Create: 새로운 리뷰를 Review 테이블에 추가하고 ID를 할당합니다.
+func (r *ReviewRepoImpl) Create(ctx context.Context, review *model.Review) error {
+	query := `
+		INSERT INTO Review (
+			restaurant_ref_id, user_ref_id, rating, review_content, reliability_weight
+		) VALUES (?, ?, ?, ?, ?)`
+
+	result, err := r.DB.ExecContext(
+		ctx,
+		query,
+		review.RestaurantRefID,
+		review.UserRefID,
+		review.Rating,
+		review.ReviewContent,
+		review.ReliabilityWeight,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read inserted review ID: %w", err)
+	}
+	review.ReviewID = lastID
+	r.Logger.Debug("review created", slog.Int64("review_id", review.ReviewID),
+		logging.RestaurantID(review.RestaurantRefID), logging.UserID(review.UserRefID))
+	return nil
+}
+
+// FindByID: review_id를 기반으로 리뷰를 조회합니다.
+func (r *ReviewRepoImpl) FindByID(ctx context.Context, reviewID int64) (*model.Review, error) {
+	query := `SELECT ` + reviewColumns + `
+		FROM Review
+		WHERE review_id = ?`
+
+	review, err := scanReview(r.DB.QueryRowContext(ctx, query, reviewID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // 리뷰 없음
+		}
+		return nil, fmt.Errorf("failed to find review by ID: %w", err)
+	}
+
+	return review, nil
+}
+
+// FindByRestaurant: 식당에 달린 리뷰를 최신순으로 조회합니다.
+func (r *ReviewRepoImpl) FindByRestaurant(ctx context.Context, restaurantID int64, limit, offset int) ([]model.Review, error) {
+	query := `SELECT ` + reviewColumns + `
+		FROM Review
+		WHERE restaurant_ref_id = ?
+		ORDER BY created_at DESC, review_id DESC
+		LIMIT ? OFFSET ?`
+
+	return r.queryReviews(ctx, query, restaurantID, limit, offset)
+}
+
+// FindByUser: 유저가 작성한 리뷰를 최신순으로 조회합니다.
+func (r *ReviewRepoImpl) FindByUser(ctx context.Context, userID int64, limit, offset int) ([]model.Review, error) {
+	query := `SELECT ` + reviewColumns + `
+		FROM Review
+		WHERE user_ref_id = ?
+		ORDER BY created_at DESC, review_id DESC
+		LIMIT ? OFFSET ?`
+
+	return r.queryReviews(ctx, query, userID, limit, offset)
+}
+
+// ConsensusDeviation: 유저가 리뷰한 식당마다 본인을 제외한 평균 평점을 구해 편차의 평균을 계산합니다.
+// 다른 리뷰가 없는 식당은 비교 대상에서 제외되며, 두 번째 반환값은 비교에 쓰인 리뷰 개수입니다.
+func (r *ReviewRepoImpl) ConsensusDeviation(ctx context.Context, userID int64) (float64, int64, error) {
+	query := `
+		SELECT
+			COALESCE(AVG(ABS(r.rating - (m.total - r.rating) / (m.cnt - 1))), 0),
+			COUNT(*)
+		FROM Review r
+		JOIN (
+			SELECT restaurant_ref_id, SUM(rating) AS total, COUNT(*) AS cnt
+			FROM Review
+			GROUP BY restaurant_ref_id
+		) m ON m.restaurant_ref_id = r.restaurant_ref_id
+		WHERE r.user_ref_id = ? AND m.cnt > 1`
+
+	var deviation float64
+	var compared int64
+	if err := r.DB.QueryRowContext(ctx, query, userID).Scan(&deviation, &compared); err != nil {
+		return 0, 0, fmt.Errorf("failed to compute consensus deviation (user %d): %w", userID, err)
+	}
+	return deviation, compared, nil
+}
+
+// WeightedRating: SUM(rating * reliability_weight) / SUM(reliability_weight)를 계산합니다.
+// 가중치가 0인(신뢰도 0) 리뷰는 평균에도, 리뷰 개수에도 포함되지 않습니다. 리뷰가 없으면 0, 0을 반환합니다.
+func (r *ReviewRepoImpl) WeightedRating(ctx context.Context, restaurantID int64) (float64, int64, error) {
+	query := `
+		SELECT
+			COALESCE(SUM(rating * reliability_weight) / SUM(reliability_weight), 0),
+			COUNT(*)
+		FROM Review
+		WHERE restaurant_ref_id = ? AND reliability_weight > 0`
+
+	var rating float64
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, query, restaurantID).Scan(&rating, &count); err != nil {
+		return 0, 0, fmt.Errorf("failed to compute weighted rating (restaurant %d): %w", restaurantID, err)
+	}
+	return rating, count, nil
+}
+
+func (r *ReviewRepoImpl) queryReviews(ctx context.Context, query string, args ...any) ([]model.Review, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []model.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// scanReview: reviewColumns 순서대로 한 행을 읽고 created_at을 파싱합니다.
+func scanReview(row rowScanner) (*model.Review, error) {
+	review := &model.Review{}
+	var createdAtStr string
+
+	err := row.Scan(
+		&review.ReviewID,
+		&review.RestaurantRefID,
+		&review.UserRefID,
+		&review.Rating,
+		&review.ReviewContent,
+		&review.ReliabilityWeight,
+		&createdAtStr,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	review.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse review created_at: %w", err)
+	}
+
+	return review, nil
+}
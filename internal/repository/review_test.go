@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// --- TDD: TestReviewCreateAndFind ---
+func TestReviewCreateAndFind(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := repository.NewReviewRepository(db)
+	ctx := context.Background()
+
+	// 1. Given: 식당 1에 3개, 식당 2에 1개의 리뷰 (유저 7이 모두 작성)
+	for i, restaurantID := range []int64{1, 1, 1, 2} {
+		review := model.Review{
+			RestaurantRefID:   restaurantID,
+			UserRefID:         7,
+			Rating:            float64(i + 1),
+			ReviewContent:     "맛있어요",
+			ReliabilityWeight: 0.8,
+		}
+		if err := repo.Create(ctx, &review); err != nil {
+			t.Fatalf("Create review failed: %v", err)
+		}
+		if review.ReviewID <= 0 {
+			t.Fatalf("Expected ReviewID to be assigned, got %d", review.ReviewID)
+		}
+	}
+
+	// 2. When/Then: 단건 조회
+	review, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if review == nil || review.Rating != 1 || review.ReliabilityWeight != 0.8 {
+		t.Errorf("Unexpected review: %+v", review)
+	}
+
+	// 3. When/Then: 식당별 페이지 조회 (최신순이므로 review_id 3, 2)
+	page, err := repo.FindByRestaurant(ctx, 1, 2, 0)
+	if err != nil {
+		t.Fatalf("FindByRestaurant failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ReviewID != 3 || page[1].ReviewID != 2 {
+		t.Errorf("Expected reviews 3,2 got %+v", page)
+	}
+	next, err := repo.FindByRestaurant(ctx, 1, 2, 2)
+	if err != nil {
+		t.Fatalf("FindByRestaurant (page 2) failed: %v", err)
+	}
+	if len(next) != 1 || next[0].ReviewID != 1 {
+		t.Errorf("Expected review 1 on second page, got %+v", next)
+	}
+
+	// 4. When/Then: 유저별 조회
+	byUser, err := repo.FindByUser(ctx, 7, 10, 0)
+	if err != nil {
+		t.Fatalf("FindByUser failed: %v", err)
+	}
+	if len(byUser) != 4 {
+		t.Errorf("Expected 4 reviews for user, got %d", len(byUser))
+	}
+}
+
+// TestConsensusDeviation: 본인을 제외한 식당 평균과의 편차 계산 테스트
+func TestConsensusDeviation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := repository.NewReviewRepository(db)
+	ctx := context.Background()
+
+	// 식당 1: 유저 1이 5점, 다른 유저 둘이 3점/4점 (합의 3.5 -> 편차 1.5)
+	// 식당 2: 유저 1만 리뷰 (비교 대상 아님)
+	for _, r := range []model.Review{
+		{RestaurantRefID: 1, UserRefID: 1, Rating: 5},
+		{RestaurantRefID: 1, UserRefID: 2, Rating: 3},
+		{RestaurantRefID: 1, UserRefID: 3, Rating: 4},
+		{RestaurantRefID: 2, UserRefID: 1, Rating: 1},
+	} {
+		r.ReviewContent = "리뷰"
+		if err := repo.Create(ctx, &r); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	deviation, compared, err := repo.ConsensusDeviation(ctx, 1)
+	if err != nil {
+		t.Fatalf("ConsensusDeviation failed: %v", err)
+	}
+	if compared != 1 || deviation != 1.5 {
+		t.Errorf("Expected deviation 1.5 over 1 review, got %.2f over %d", deviation, compared)
+	}
+}
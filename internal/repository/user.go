@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"restaurant_db/internal/logging"
 	"restaurant_db/internal/model"
 )
 
@@ -14,14 +16,17 @@ type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	FindByID(ctx context.Context, userID int64) (*model.User, error)
 	UpdateReliabilityScore(ctx context.Context, userID int64, newScore float64, newReviewCount int64, newBiasCount int64) error
+	// AdjustReliability: 현재 값에 변화량을 더합니다. (delta 모드 User 로그 반영용)
+	AdjustReliability(ctx context.Context, userID int64, scoreDelta float64, reviewCountDelta int64, biasCountDelta int64) error
 }
 
 type UserRepoImpl struct {
-	DB *sql.DB
+	DB     DBTX
+	Logger *slog.Logger // 생성/신뢰도 갱신 이벤트
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
-	return &UserRepoImpl{DB: db}
+func NewUserRepository(db DBTX, opts ...Option) UserRepository {
+	return &UserRepoImpl{DB: db, Logger: componentLogger(opts, "user_repository")}
 }
 
 // Create: 새로운 유저를 User 테이블에 추가하고 ID를 할당합니다.
@@ -36,9 +41,11 @@ func (r *UserRepoImpl) Create(ctx context.Context, user *model.User) error {
 	}
 
 	lastID, err := result.LastInsertId()
-	if err == nil {
-		user.UserID = lastID
+	if err != nil {
+		return fmt.Errorf("failed to read inserted user ID: %w", err)
 	}
+	user.UserID = lastID
+	r.Logger.Debug("user created", logging.UserID(user.UserID))
 	return nil
 }
 
@@ -71,7 +78,6 @@ func (r *UserRepoImpl) FindByID(ctx context.Context, userID int64) (*model.User,
 		return nil, fmt.Errorf("failed to find user by ID: %w", err)
 	}
 
-	const sqliteTimeFormat = "2006-01-02 15:04:05"
 	user.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAtStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse user created_at: %w", err)
@@ -109,5 +115,33 @@ func (r *UserRepoImpl) UpdateReliabilityScore(
 		return fmt.Errorf("failed to update user reliability score (ID: %d): %w", userID, err)
 	}
 
+	r.Logger.Debug("user reliability set", logging.UserID(userID), slog.Float64("score", newScore),
+		slog.Int64("review_count", newReviewCount), slog.Int64("bias_count", newBiasCount))
+	return nil
+}
+
+// AdjustReliability: 신뢰도 점수와 카운트에 변화량을 더합니다. 점수는 0 ~ 1 범위로 제한됩니다.
+func (r *UserRepoImpl) AdjustReliability(
+	ctx context.Context,
+	userID int64,
+	scoreDelta float64,
+	reviewCountDelta int64,
+	biasCountDelta int64,
+) error {
+	query := `
+		UPDATE User
+		SET
+			reliability_score = MIN(1.0, MAX(0.0, reliability_score + ?)),
+			review_count = review_count + ?,
+			bias_count = bias_count + ?
+		WHERE user_id = ?`
+
+	_, err := r.DB.ExecContext(ctx, query, scoreDelta, reviewCountDelta, biasCountDelta, userID)
+	if err != nil {
+		return fmt.Errorf("failed to adjust user reliability (ID: %d): %w", userID, err)
+	}
+
+	r.Logger.Debug("user reliability adjusted", logging.UserID(userID), slog.Float64("score_delta", scoreDelta),
+		slog.Int64("review_count_delta", reviewCountDelta), slog.Int64("bias_count_delta", biasCountDelta))
 	return nil
 }
@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 적응형 배치 크기 기본 설정
+const (
+	DefaultMinBatchSize = 10
+	DefaultMaxBatchSize = 1000
+	DefaultMinInterval  = 10 * time.Millisecond
+	DefaultFreshnessSLO = time.Second
+
+	// latencySmoothing: 로그 한 건당 반영 시간의 지수 이동 평균 가중치 (새 관측값 쪽)
+	latencySmoothing = 0.3
+)
+
+// AdaptiveConfig: AdaptiveController의 목표와 범위
+type AdaptiveConfig struct {
+	// FreshnessSLO: AddLog 후 릴레이션에 반영되기까지 허용하는 시간
+	FreshnessSLO time.Duration
+	// MinBatchSize, MaxBatchSize: BatchSize를 조정할 범위
+	MinBatchSize int
+	MaxBatchSize int
+	// MinInterval, MaxInterval: 첫 알림 후 반영까지 기다리는 시간(MaxLatency)을 조정할 범위
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// ApplyBudget: 배치 하나를 반영하는 데 쓸 수 있는 시간 (이보다 오래 걸리면 lease와 쓰기 잠금을 오래 잡으므로 배치를 줄임, 0이면 FreshnessSLO의 1/4)
+	ApplyBudget time.Duration
+}
+
+// DefaultAdaptiveConfig: freshnessSLO를 목표로 하는 기본 범위
+func DefaultAdaptiveConfig(freshnessSLO time.Duration) AdaptiveConfig {
+	return AdaptiveConfig{
+		FreshnessSLO: freshnessSLO,
+		MinBatchSize: DefaultMinBatchSize,
+		MaxBatchSize: DefaultMaxBatchSize,
+		MinInterval:  DefaultMinInterval,
+		MaxInterval:  freshnessSLO / 2,
+	}
+}
+
+// AdaptiveDecision: 컨트롤러의 현재 결정과 그 근거가 된 관측값
+type AdaptiveDecision struct {
+	BatchSize  int           // 한 번에 가져올 로그 수 (알림이 이만큼 쌓이면 바로 반영)
+	MaxLatency time.Duration // 첫 알림 후 반영까지 기다리는 시간
+
+	Backlog      int64         // 마지막 배치 후 남은 반영 대기 로그 수
+	ApplyLatency time.Duration // 마지막 배치의 반영 시간
+	PerLog       time.Duration // 로그 한 건당 반영 시간의 이동 평균
+	// EstimatedFreshness: 지금 들어온 로그가 반영되기까지 걸릴 것으로 보는 시간 (대기 + 남은 로그 반영)
+	EstimatedFreshness time.Duration
+	// Behind: 범위 안에서 최대한 조정해도 FreshnessSLO를 지키지 못하는 상태
+	Behind    bool
+	UpdatedAt time.Time
+}
+
+func (d AdaptiveDecision) String() string {
+	return fmt.Sprintf("batch=%d maxLatency=%s backlog=%d perLog=%s freshness=%s behind=%t",
+		d.BatchSize, d.MaxLatency, d.Backlog, d.PerLog, d.EstimatedFreshness, d.Behind)
+}
+
+// AdaptiveController는 CheckpointWorker의 BatchSize와 MaxLatency를 관측값에 맞춰 조정합니다.
+// 남은 로그가 많으면 배치를 키우고 기다리지 않으며, 배치 반영이 ApplyBudget을 넘으면 배치를 줄이고,
+// 한가하면 FreshnessSLO 안에서 더 오래 기다려 한 번에 모아 반영합니다. 한 번에 2배 넘게 바꾸지 않아 흔들림을 줄입니다.
+type AdaptiveController struct {
+	Config AdaptiveConfig
+
+	mu       sync.Mutex
+	decision AdaptiveDecision
+}
+
+// NewAdaptiveController: 처음 결정은 범위의 하한 배치와 상한 대기 시간입니다. (관측 전에는 한가하다고 봄)
+func NewAdaptiveController(config AdaptiveConfig) *AdaptiveController {
+	if config.MinBatchSize < 1 {
+		config.MinBatchSize = 1
+	}
+	if config.MaxBatchSize < config.MinBatchSize {
+		config.MaxBatchSize = config.MinBatchSize
+	}
+	if config.MaxInterval < config.MinInterval {
+		config.MaxInterval = config.MinInterval
+	}
+	if config.ApplyBudget <= 0 {
+		config.ApplyBudget = config.FreshnessSLO / 4
+	}
+
+	return &AdaptiveController{
+		Config: config,
+		decision: AdaptiveDecision{
+			BatchSize:  config.MinBatchSize,
+			MaxLatency: config.MaxInterval,
+		},
+	}
+}
+
+// Decision: 현재 결정
+func (c *AdaptiveController) Decision() AdaptiveDecision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.decision
+}
+
+// Observe: 배치 하나의 결과(가져온 로그 수, 반영 시간)와 그 뒤에 남은 로그 수로 다음 결정을 계산합니다.
+func (c *AdaptiveController) Observe(fetched int, applyLatency time.Duration, backlog int64) AdaptiveDecision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cfg := c.Config
+	d := c.decision
+	d.Backlog = backlog
+	d.UpdatedAt = time.Now()
+	if fetched > 0 {
+		d.ApplyLatency = applyLatency
+		perLog := applyLatency / time.Duration(fetched)
+		if d.PerLog == 0 {
+			d.PerLog = perLog
+		} else {
+			d.PerLog = time.Duration(latencySmoothing*float64(perLog) + (1-latencySmoothing)*float64(d.PerLog))
+		}
+	}
+
+	// 배치 크기: 남은 로그를 한 번에 가져올 수 있게 하되, 배치 하나의 반영 시간이 ApplyBudget을 넘지 않게 함
+	target := int(backlog)
+	if d.PerLog > 0 {
+		if limit := int(cfg.ApplyBudget / d.PerLog); target > limit {
+			target = limit
+		}
+	}
+	d.BatchSize = clampInt(target, d.BatchSize/2, d.BatchSize*2)
+	d.BatchSize = clampInt(d.BatchSize, cfg.MinBatchSize, cfg.MaxBatchSize)
+
+	// 대기 시간: 남은 로그를 반영하는 데 걸릴 시간을 FreshnessSLO에서 빼고 남는 만큼만 기다림
+	drainTime := time.Duration(backlog) * d.PerLog
+	d.MaxLatency = clampDuration(cfg.FreshnessSLO-drainTime, cfg.MinInterval, cfg.MaxInterval)
+
+	d.EstimatedFreshness = d.MaxLatency + drainTime
+	d.Behind = d.EstimatedFreshness > cfg.FreshnessSLO
+
+	c.decision = d
+	return d
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func clampDuration(v, lo, hi time.Duration) time.Duration {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package worker_test
+
+import (
+	"testing"
+	"time"
+
+	"restaurant_db/internal/worker"
+)
+
+func TestAdaptiveControllerTracksBacklog(t *testing.T) {
+	// 1. Given: 목표 1초, 배치 10 ~ 1000, 대기 10ms ~ 500ms (배치 하나는 250ms 안에 반영)
+	c := worker.NewAdaptiveController(worker.DefaultAdaptiveConfig(time.Second))
+	if d := c.Decision(); d.BatchSize != 10 || d.MaxLatency != 500*time.Millisecond {
+		t.Fatalf("Expected to start idle (batch 10, wait 500ms), got %s", d)
+	}
+
+	// 2. When & 3. Then: 로그가 빨리 반영되고(건당 10µs) 백로그가 깊으면 배치가 한 번에 2배씩 커지다 상한에서 멈춤
+	var d worker.AdaptiveDecision
+	prev := c.Decision().BatchSize
+	for i := 0; i < 10; i++ {
+		d = c.Observe(prev, time.Duration(prev)*10*time.Microsecond, 100000)
+		if d.BatchSize > prev*2 {
+			t.Fatalf("Expected batch to at most double per step, got %d -> %d", prev, d.BatchSize)
+		}
+		prev = d.BatchSize
+	}
+	if d.BatchSize != 1000 {
+		t.Fatalf("Expected batch to reach the max, got %s", d)
+	}
+	// 남은 로그를 반영하는 데만 1초가 걸리므로 기다리지 않고, SLO를 못 지키는 상태로 보고
+	if d.MaxLatency != 10*time.Millisecond || !d.Behind {
+		t.Fatalf("Expected minimum wait and behind SLO, got %s", d)
+	}
+
+	// 반영이 느려지면(건당 1ms) 배치 하나가 250ms를 넘지 않도록 줄어듦
+	for i := 0; i < 20; i++ {
+		d = c.Observe(d.BatchSize, time.Duration(d.BatchSize)*time.Millisecond, 100000)
+	}
+	if d.BatchSize > 250 || d.BatchSize < 10 {
+		t.Fatalf("Expected batch to fit the apply budget, got %s", d)
+	}
+
+	// 백로그가 비면 하한 배치와 상한 대기 시간으로 돌아가 모아서 반영
+	for i := 0; i < 20; i++ {
+		d = c.Observe(0, 0, 0)
+	}
+	if d.BatchSize != 10 || d.MaxLatency != 500*time.Millisecond || d.Behind {
+		t.Fatalf("Expected idle decision, got %s", d)
+	}
+}
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/reliability"
+	"restaurant_db/internal/repository"
+)
+
+// AnalysisWorker는 PENDING 상태의 Review_Analysis_Log를 읽어 유저 신뢰도를 다시 계산하고,
+// 그 결과를 User UPDATE 로그로 Buffer_Log에 넘깁니다. (실제 User 반영은 CheckpointWorker가 담당)
+type AnalysisWorker struct {
+	AnalysisRepo repository.AnalysisLogRepository
+	UserRepo     repository.UserRepository
+	BufferRepo   repository.BufferRepository
+	// Scorer: 신뢰도 계산 전략 (설정으로 교체 가능)
+	Scorer reliability.ReliabilityScorer
+
+	BatchSize int
+	Interval  time.Duration
+
+	Logger *slog.Logger
+
+	processing sync.Mutex    // ProcessAnalysis를 한 번에 하나만 실행 (Run과 직접 호출이 겹치지 않게)
+	stop       chan struct{} // Stop이 닫으면 Run이 진행 중인 배치를 마치고 반환
+	stopOnce   sync.Once
+	runMu      sync.Mutex
+	runDone    chan struct{} // 실행 중인 Run이 반환하면 닫힘 (Run이 시작되지 않았으면 nil)
+}
+
+// AnalysisResult: ProcessAnalysis 한 번의 결과
+type AnalysisResult struct {
+	Fetched  int // 읽어 온 PENDING 분석 요청 수
+	Buffered int // User 로그를 버퍼에 남기고 BUFFERED로 표시한 수
+	Failed   int // FAILED로 표시한 수 (나머지는 PENDING으로 남아 다음 배치에서 다시 처리)
+}
+
+func NewAnalysisWorker(
+	analysisRepo repository.AnalysisLogRepository,
+	userRepo repository.UserRepository,
+	bufferRepo repository.BufferRepository,
+	scorer reliability.ReliabilityScorer,
+	batchSize int,
+	interval time.Duration,
+	logger *slog.Logger,
+) *AnalysisWorker {
+	return &AnalysisWorker{
+		AnalysisRepo: analysisRepo,
+		UserRepo:     userRepo,
+		BufferRepo:   bufferRepo,
+		Scorer:       scorer,
+		BatchSize:    batchSize,
+		Interval:     interval,
+		Logger:       logging.Component(logger, "analysis_worker"),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Run: 주기적으로 분석 요청을 처리하는 메인 루프
+// ctx가 취소되면 바로 반환하고, 정상 종료는 Stop을 사용합니다.
+func (w *AnalysisWorker) Run(ctx context.Context) {
+	done := make(chan struct{})
+	defer close(done)
+	w.runMu.Lock()
+	w.runDone = done
+	w.runMu.Unlock()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	w.Logger.Info("analysis worker started", slog.Duration("interval", w.Interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.Logger.Info("analysis worker stopped")
+			return
+		case <-w.stop:
+			w.Logger.Info("analysis worker stopping")
+			return
+		case <-ticker.C:
+			w.ProcessAnalysis(ctx)
+		}
+	}
+}
+
+// Stop: Run에 멈추라고 알리고 진행 중인 배치가 끝나기를 기다린 뒤, ctx가 끝날 때까지 남은 분석 요청을 처리합니다.
+// Run이 시작되지 않았으면 바로 처리하기 시작합니다. 다 처리하기 전에 ctx가 끝나면 ctx.Err()를 반환합니다.
+// 남긴 User 로그가 함께 반영되도록 CheckpointWorker.Stop보다 먼저 호출합니다.
+func (w *AnalysisWorker) Stop(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.runMu.Lock()
+	done := w.runDone
+	w.runMu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var buffered int
+	for ctx.Err() == nil {
+		result := w.ProcessAnalysis(ctx)
+		buffered += result.Buffered
+		// 배치보다 적게 읽었거나 하나도 처리하지 못했으면(표시 실패 등) 더 돌아도 줄지 않음
+		if result.Fetched < w.BatchSize || result.Buffered+result.Failed == 0 {
+			break
+		}
+	}
+	w.Logger.Info("analysis worker drained", slog.Int("buffered", buffered))
+	return ctx.Err()
+}
+
+// ProcessAnalysis: PENDING 분석 요청을 한 배치 처리합니다.
+// 같은 배치 안에서 같은 유저의 요청이 여러 개면 앞선 계산 결과 위에 누적해 점수를 계산합니다.
+// (User 테이블은 CheckpointWorker가 반영하기 전까지 갱신되지 않으므로 DB 값만 다시 읽으면 앞선 결과가 점수에서 빠짐)
+// 카운트는 변화량(delta) 로그로 남기므로, 배치가 나뉘어 반영 전의 User를 다시 읽어도 증가분을 잃지 않습니다.
+func (w *AnalysisWorker) ProcessAnalysis(ctx context.Context) AnalysisResult {
+	w.processing.Lock()
+	defer w.processing.Unlock()
+
+	var result AnalysisResult
+	pending, err := w.AnalysisRepo.GetPending(ctx, w.BatchSize)
+	if err != nil {
+		w.Logger.Error("failed to get pending analysis logs", logging.Err(err))
+		return result
+	}
+	result.Fetched = len(pending)
+	if len(pending) == 0 {
+		return result
+	}
+
+	start := time.Now()
+	users := make(map[int64]*model.User)
+
+	for _, analysis := range pending {
+		change, err := w.processAnalysis(ctx, analysis, users)
+		if err != nil {
+			w.Logger.Warn("failed to process analysis log", slog.Int64("analysis_log_id", analysis.AnalysisLogID),
+				logging.UserID(analysis.UserRefID), logging.Err(err))
+			if err := w.AnalysisRepo.MarkFailed(ctx, analysis.AnalysisLogID); err != nil {
+				w.Logger.Error("failed to mark analysis log failed", slog.Int64("analysis_log_id", analysis.AnalysisLogID), logging.Err(err))
+				continue
+			}
+			result.Failed++
+			continue
+		}
+
+		// 로그는 이미 버퍼에 있으므로 표시에 실패하면 PENDING으로 남겨 다음 배치에서 다시 처리
+		// (같은 멱등성 키로 다시 추가하므로 User 로그가 두 번 쌓이지 않음)
+		if err := w.AnalysisRepo.MarkBuffered(ctx, analysis.AnalysisLogID, change); err != nil {
+			w.Logger.Error("failed to mark analysis log buffered", slog.Int64("analysis_log_id", analysis.AnalysisLogID), logging.Err(err))
+			continue
+		}
+		result.Buffered++
+	}
+
+	w.Logger.Info("analysis batch processed", logging.BatchSize(len(pending)),
+		slog.Int("buffered", result.Buffered), slog.Int("failed", result.Failed), logging.Duration(time.Since(start)))
+	return result
+}
+
+// processAnalysis: 분석 요청 한 건의 신뢰도 변화량을 계산하고 User delta 로그를 버퍼에 남긴 뒤, 그 변화량을 반환합니다.
+// 로그에는 "analysis:<analysis_log_id>" 멱등성 키를 붙여, 같은 요청을 다시 처리해도 로그가 한 번만 추가되게 합니다.
+func (w *AnalysisWorker) processAnalysis(ctx context.Context, analysis model.ReviewAnalysisLog, users map[int64]*model.User) (float64, error) {
+	user, ok := users[analysis.UserRefID]
+	if !ok {
+		found, err := w.UserRepo.FindByID(ctx, analysis.UserRefID)
+		if err != nil {
+			return 0, err
+		}
+		if found == nil {
+			return 0, fmt.Errorf("user %d not found", analysis.UserRefID)
+		}
+		user = found
+	}
+
+	// 1. 이 리뷰를 반영한 카운트로 새 신뢰도를 계산
+	next := *user
+	next.ReviewCount++
+	next.BiasCount += analysis.NewBiasCount
+	score, err := w.Scorer.Score(ctx, reliability.Input{
+		UserID:       next.UserID,
+		ReviewCount:  next.ReviewCount,
+		BiasCount:    next.BiasCount,
+		CurrentScore: user.ReliabilityScore,
+	})
+	if err != nil {
+		return 0, err
+	}
+	next.ReliabilityScore = reliability.Clamp(score)
+	change := next.ReliabilityScore - user.ReliabilityScore
+
+	// 2. 변화량을 User delta 로그로 버퍼에 기록
+	bufferLog, err := buffer.NewUserDeltaLog(next.UserID, change, 1, analysis.NewBiasCount)
+	if err != nil {
+		return 0, err
+	}
+	bufferLog.IdempotencyKey = fmt.Sprintf("analysis:%d", analysis.AnalysisLogID)
+	if err := w.BufferRepo.AddLog(ctx, &bufferLog); err != nil {
+		return 0, err
+	}
+
+	// 3. 배치 내 누적 상태를 갱신
+	users[next.UserID] = &next
+	return change, nil
+}
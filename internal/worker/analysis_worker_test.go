@@ -0,0 +1,198 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/reliability"
+	"restaurant_db/internal/repository"
+	"restaurant_db/internal/worker"
+)
+
+// TestAnalysisWorkerBuffersUserUpdate: 분석 요청이 User UPDATE 로그로 변환되고, CheckpointWorker가 이를 User에 반영하는 전체 흐름을 확인합니다.
+func TestAnalysisWorkerBuffersUserUpdate(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	analysisRepo := repository.NewAnalysisLogRepository(conn)
+
+	// 1. Given: 유저 한 명과, 그 유저의 리뷰 4개에 대한 분석 요청 (그 중 1개가 극단적 평점)
+	user := model.User{Username: "analyzed"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	for i, bias := range []int64{1, 0, 0, 0} {
+		if err := analysisRepo.Create(ctx, &model.ReviewAnalysisLog{
+			ReviewRefID:  int64(i + 1),
+			UserRefID:    user.UserID,
+			NewBiasCount: bias,
+		}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	// 존재하지 않는 유저에 대한 요청은 FAILED 처리되어야 함
+	if err := analysisRepo.Create(ctx, &model.ReviewAnalysisLog{ReviewRefID: 5, UserRefID: 999}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// 2. When: 분석 후 체크포인트
+	analysisWorker := worker.NewAnalysisWorker(analysisRepo, userRepo, bufferRepo, reliability.BiasRatioScorer{}, 10, time.Minute, nil)
+	analysisWorker.ProcessAnalysis(ctx)
+
+	remaining, err := analysisRepo.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPending failed: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected no pending analysis logs, got %d", len(remaining))
+	}
+	var failed int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Review_Analysis_Log WHERE status = 'FAILED'").Scan(&failed); err != nil {
+		t.Fatalf("Failed to count failed logs: %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 FAILED analysis log, got %d", failed)
+	}
+
+	checkpoint := worker.NewCheckpointWorker(conn, nil, 100, time.Minute, nil)
+	checkpoint.ProcessCheckpoint(ctx)
+
+	// 3. Then: 4개 리뷰 중 1개가 극단적이므로 신뢰도 0.75, 카운트 4/1
+	updated, err := userRepo.FindByID(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if updated.ReviewCount != 4 || updated.BiasCount != 1 {
+		t.Errorf("Expected counts 4/1, got %d/%d", updated.ReviewCount, updated.BiasCount)
+	}
+	if updated.ReliabilityScore != 0.75 {
+		t.Errorf("Expected reliability 0.75, got %.2f", updated.ReliabilityScore)
+	}
+}
+
+// flakyAnalysisRepo: 처음 한 번의 MarkBuffered만 실패시킵니다.
+type flakyAnalysisRepo struct {
+	repository.AnalysisLogRepository
+	failed bool
+}
+
+func (r *flakyAnalysisRepo) MarkBuffered(ctx context.Context, analysisLogID int64, change float64) error {
+	if !r.failed {
+		r.failed = true
+		return errors.New("mark buffered failed")
+	}
+	return r.AnalysisLogRepository.MarkBuffered(ctx, analysisLogID, change)
+}
+
+// TestAnalysisWorkerKeepsCountsAcrossBatches: 반영 전의 User를 배치마다 다시 읽거나, BUFFERED 표시에 실패해 같은 요청을 다시 처리해도
+// 카운트 증가분이 빠지거나 두 번 쌓이지 않는지 확인합니다.
+func TestAnalysisWorkerKeepsCountsAcrossBatches(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	analysisRepo := &flakyAnalysisRepo{AnalysisLogRepository: repository.NewAnalysisLogRepository(conn)}
+
+	// 1. Given: 유저 한 명의 분석 요청 2개, 첫 요청의 BUFFERED 표시는 한 번 실패
+	user := model.User{Username: "analyzed"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	for i, bias := range []int64{1, 0} {
+		if err := analysisRepo.Create(ctx, &model.ReviewAnalysisLog{
+			ReviewRefID:  int64(i + 1),
+			UserRefID:    user.UserID,
+			NewBiasCount: bias,
+		}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	// 2. When: 배치 크기 1로 세 번 처리 (실패한 요청 재처리 포함) 후 체크포인트
+	analysisWorker := worker.NewAnalysisWorker(analysisRepo, userRepo, bufferRepo, reliability.BiasRatioScorer{}, 1, time.Minute, nil)
+	for i := 0; i < 3; i++ {
+		analysisWorker.ProcessAnalysis(ctx)
+	}
+	remaining, err := analysisRepo.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPending failed: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected no pending analysis logs, got %d", len(remaining))
+	}
+	var logs int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Buffer_Log WHERE target_table = 'User'").Scan(&logs); err != nil {
+		t.Fatalf("Failed to count buffer logs: %v", err)
+	}
+	if logs != 2 {
+		t.Errorf("Expected 2 User buffer logs, got %d", logs)
+	}
+
+	checkpoint := worker.NewCheckpointWorker(conn, nil, 100, time.Minute, nil)
+	checkpoint.ProcessCheckpoint(ctx)
+
+	// 3. Then: 두 리뷰의 카운트가 모두 반영됨
+	updated, err := userRepo.FindByID(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if updated.ReviewCount != 2 || updated.BiasCount != 1 {
+		t.Errorf("Expected counts 2/1, got %d/%d", updated.ReviewCount, updated.BiasCount)
+	}
+}
+
+// TestAnalysisWorkerStopDrainsPending: Stop이 Run을 멈추고, 주기를 기다리지 않고 남은 분석 요청을 모두 처리하는지 확인합니다.
+func TestAnalysisWorkerStopDrainsPending(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	analysisRepo := repository.NewAnalysisLogRepository(conn)
+
+	// 1. Given: 주기가 매우 긴 Run과 배치 크기(2)보다 많은 분석 요청
+	user := model.User{Username: "stopping"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	analysisWorker := worker.NewAnalysisWorker(analysisRepo, userRepo, bufferRepo, reliability.BiasRatioScorer{}, 2, time.Hour, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		analysisWorker.Run(ctx)
+	}()
+	for i := int64(1); i <= 5; i++ {
+		if err := analysisRepo.Create(ctx, &model.ReviewAnalysisLog{ReviewRefID: i, UserRefID: user.UserID}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	// 2. When
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := analysisWorker.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// 3. Then: Run이 반환하고, 요청은 모두 User 로그로 넘어감
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return after Stop")
+	}
+	if remaining, _ := analysisRepo.GetPending(ctx, 10); len(remaining) != 0 {
+		t.Errorf("Expected no pending analysis logs, got %d", len(remaining))
+	}
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 10); len(pending) != 5 {
+		t.Errorf("Expected 5 buffered User logs, got %d", len(pending))
+	}
+}
@@ -2,114 +2,703 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/metrics"
 	"restaurant_db/internal/model"
 	"restaurant_db/internal/repository"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// CheckpointWorker는 주기적으로 Buffer_Log를 읽어 실제 DB에 반영합니다.
+// logSavepoint: 트랜잭션 안에서 로그 한 건의 반영을 되돌리기 위한 SAVEPOINT 이름
+const logSavepoint = "checkpoint_log"
+
+// DefaultLeaseDuration: Worker가 가져간 로그를 다른 Worker가 다시 가져갈 수 있기까지의 기본 시간
+const DefaultLeaseDuration = 30 * time.Second
+
+// DefaultPollInterval: 알림 없이 처리 가능해진 로그(다른 프로세스의 AddLog, 재시도 대기, 만료된 lease)를 찾는 기본 주기
+const DefaultPollInterval = 30 * time.Second
+
+// workerSeq: 같은 프로세스 안의 Worker ID를 구분하기 위한 일련번호
+var workerSeq atomic.Int64
+
+// CheckpointWorker는 Buffer_Log를 읽어 실제 DB에 반영합니다.
+// Run은 반영 대기 로그가 BatchSize만큼 쌓이거나, 첫 로그 알림 후 MaxLatency가 지나거나, RequestFlush가 호출되면
+// (그중 먼저 오는 조건에서) 버퍼를 비우고, 그 밖에는 PollInterval마다 한 번만 확인합니다. (알림은 LogAdded로 받음)
+// 로그 반영과 is_committed 갱신은 (서브)배치 단위로 하나의 트랜잭션 안에서 함께 커밋됩니다.
+// 로그는 WorkerID 이름의 lease로 가져가므로 여러 Worker(고루틴 또는 프로세스)가 동시에 버퍼를 비울 수 있고,
+// 중간에 죽은 Worker의 로그는 lease가 만료되면 다른 Worker가 다시 가져갑니다.
 type CheckpointWorker struct {
+	// WorkerID: claim에 기록되는 이름 (Worker마다 달라야 함)
+	WorkerID string
+	// LeaseDuration: 가져간 로그를 독점하는 시간 (한 배치를 처리하는 시간보다 길어야 함)
+	LeaseDuration time.Duration
+
+	// DB: 배치마다 트랜잭션을 여는 연결 (트랜잭션 안의 Repository는 repository.NewRepositories(tx)로 만듦)
+	DB         *sql.DB
 	BufferRepo repository.BufferRepository
-	UserRepo   repository.UserRepository
+	// Registry: 로그의 (테이블, 트랜잭션 종류)별 반영/합치기 핸들러 (등록되지 않은 조합은 Dead Letter로 거절)
+	Registry *buffer.Registry
+	// Aggregator: 커밋된 배치가 건드린 식당의 Cache_Metadata를 선제적으로 갱신 (nil이면 생략)
+	Aggregator *cache.RatingAggregator
+
+	// Retry: 반영 실패 시 재시도 간격과 Dead Letter로 옮기는 기준
+	Retry RetryPolicy
 
 	BatchSize int
-	Interval  time.Duration
+	// SubBatchSize: 트랜잭션 하나에 담는 최대 로그 수 (0이면 배치 전체를 한 트랜잭션으로 반영)
+	SubBatchSize int
+	// Coalesce: 트랜잭션마다 같은 레코드에 대한 연속된 업데이트를 하나의 쓰기로 합쳐 반영 (기본값 true)
+	Coalesce bool
+	// Parallelism: 배치를 레코드 키별 파티션으로 나눠 동시에 반영할 고루틴 수 (1 이하이면 순차 반영)
+	// 같은 레코드의 로그는 한 파티션 안에서 순서대로 반영되고, 파티션마다 별도의 트랜잭션을 씁니다.
+	// 인메모리 shared-cache DB는 동시 쓰기 트랜잭션을 허용하지 않으므로 파일 DB에서만 사용합니다.
+	Parallelism int
+
+	// MaxLatency: 알림을 받은 로그가 반영되기까지 기다리는 최대 시간
+	MaxLatency time.Duration
+	// PollInterval: 알림과 관계없이 버퍼를 확인하는 주기 (0 이하이면 알림과 RequestFlush로만 깨어남)
+	PollInterval time.Duration
+	// Adaptive: 있으면 BatchSize와 MaxLatency 대신 배치마다 조정되는 컨트롤러의 결정을 사용
+	Adaptive *AdaptiveController
+
+	// Logger: 배치 반영, 실패, 캐시 갱신 이벤트 (기록할 때 worker_id를 붙임)
+	Logger *slog.Logger
+	// Metrics: 배치 지연 시간과 결과별 로그 수 (nil이면 기록하지 않음)
+	Metrics *metrics.Metrics
+
+	notified atomic.Int64  // 마지막으로 버퍼를 비운 뒤 알림을 받은 로그 수
+	arrived  chan struct{} // 첫 알림: MaxLatency 타이머 시작
+	flush    chan struct{} // BatchSize 도달 또는 RequestFlush: 바로 비움
+
+	processing sync.Mutex    // 같은 Worker의 ProcessCheckpoint를 한 번에 하나만 실행 (Run과 Flush가 겹치지 않게)
+	stop       chan struct{} // Stop이 닫으면 Run이 진행 중인 배치를 마치고 반환
+	stopOnce   sync.Once
+	runMu      sync.Mutex
+	runDone    chan struct{} // 실행 중인 Run이 반환하면 닫힘 (Run이 시작되지 않았으면 nil)
 }
 
+// DrainReport: Stop의 결과
+type DrainReport struct {
+	CheckpointResult       // Run을 멈춘 뒤 남은 로그를 비우며 반영한 결과
+	Remaining        int64 // 그래도 반영되지 않고 남은 로그 수 (재시도 대기, 다른 Worker가 가져간 로그 포함)
+	TimedOut         bool  // ctx가 끝나 다 비우지 못하고 멈췄는지
+}
+
+// CheckpointResult: ProcessCheckpoint 한 번의 결과
+type CheckpointResult struct {
+	Fetched      int // 버퍼에서 읽은 로그 수
+	Committed    int // 반영 후 is_committed = 1이 된 로그 수
+	Applied      int // Committed를 위해 실제로 실행한 쓰기 수 (합치기 후)
+	Failed       int // 반영에 실패한 로그 수 (재시도 대기 + Dead Letter + 롤백된 트랜잭션의 로그)
+	Retried      int // 실패 후 재시도 대기로 남은 로그 수
+	DeadLettered int // 재시도를 포기하고 Dead Letter로 옮긴 로그 수 (Rejected 포함)
+	Rejected     int // 처리할 수 없는 로그(등록되지 않은 Route 등)라 재시도 없이 Dead Letter로 옮긴 로그 수
+	Deferred     int // 같은 레코드의 앞선 로그가 실패해 순서를 지키려고 반영하지 않고 돌려보낸 로그 수
+	Transactions int // 커밋된 트랜잭션 수
+	// Err: 로그를 가져오지 못했거나 트랜잭션이 롤백된 첫 오류 (나머지 수는 그때까지의 결과)
+	Err error
+}
+
+// NewCheckpointWorker: maxLatency는 알림을 받은 로그가 반영되기까지 기다리는 최대 시간입니다.
+// 알림을 받으려면 BufferRepository에 repository.WithNotifier(w)로 연결합니다. logger가 nil이면 slog.Default를 사용합니다.
 func NewCheckpointWorker(
-	bufferRepo repository.BufferRepository,
-	userRepo repository.UserRepository,
+	db *sql.DB,
+	aggregator *cache.RatingAggregator,
 	batchSize int,
-	interval time.Duration,
+	maxLatency time.Duration,
+	logger *slog.Logger,
 ) *CheckpointWorker {
 	return &CheckpointWorker{
-		BufferRepo: bufferRepo,
-		UserRepo:   userRepo,
-		BatchSize:  batchSize,
-		Interval:   interval,
+		WorkerID:      newWorkerID(),
+		LeaseDuration: DefaultLeaseDuration,
+		DB:            db,
+		BufferRepo:    repository.NewBufferRepository(db),
+		Registry:      buffer.DefaultRegistry(),
+		Aggregator:    aggregator,
+		Retry:         DefaultRetryPolicy(),
+		Coalesce:      true,
+		BatchSize:     batchSize,
+		MaxLatency:    maxLatency,
+		PollInterval:  DefaultPollInterval,
+		Logger:        logging.Component(logger, "checkpoint_worker"),
+		arrived:       make(chan struct{}, 1),
+		flush:         make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+}
+
+// newWorkerID: 호스트 이름, PID, 일련번호로 프로세스 간에도 겹치지 않는 Worker ID를 만듭니다.
+func newWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
 	}
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), workerSeq.Add(1))
 }
 
-// Run: 워커를 시작하는 메인 루프 (성능 분석 시 시뮬레이션에 사용됨)
+// batchSize: 지금 한 번에 가져올 로그 수 (Adaptive가 있으면 그 결정)
+func (w *CheckpointWorker) batchSize() int {
+	if w.Adaptive != nil {
+		return w.Adaptive.Decision().BatchSize
+	}
+	return w.BatchSize
+}
+
+// maxLatency: 지금 첫 알림 후 기다릴 시간 (Adaptive가 있으면 그 결정)
+func (w *CheckpointWorker) maxLatency() time.Duration {
+	if w.Adaptive != nil {
+		return w.Adaptive.Decision().MaxLatency
+	}
+	return w.MaxLatency
+}
+
+// logger: worker_id를 붙인 Logger
+func (w *CheckpointWorker) logger() *slog.Logger {
+	logger := w.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(logging.WorkerID(w.WorkerID))
+}
+
+// LogAdded: repository.LogNotifier 구현. 첫 알림은 MaxLatency 타이머를 시작하고, BatchSize번째부터의 알림은 바로 깨웁니다.
+// (적응형 조정으로 BatchSize가 이미 쌓인 알림 수 아래로 줄어도 놓치지 않도록 같을 때가 아니라 넘었을 때도 깨움)
+func (w *CheckpointWorker) LogAdded(model.BufferLog) {
+	n := w.notified.Add(1)
+	if n == 1 {
+		signal(w.arrived)
+	}
+	if n >= int64(w.batchSize()) {
+		signal(w.flush)
+	}
+}
+
+// RequestFlush: 조건을 기다리지 않고 Run이 바로 버퍼를 비우도록 요청합니다. (기다리지 않고 반환)
+func (w *CheckpointWorker) RequestFlush() {
+	signal(w.flush)
+}
+
+// signal: 이미 신호가 대기 중이면 버립니다. (채널 용량 1)
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Run: 워커를 시작하는 메인 루프. 깨어날 때마다 남은 로그가 BatchSize보다 적어질 때까지 연달아 반영합니다.
+// ctx가 취소되면 바로 반환하고(진행 중인 트랜잭션은 롤백되어 로그가 남음), 정상 종료는 Stop을 사용합니다.
 func (w *CheckpointWorker) Run(ctx context.Context) {
-	ticker := time.NewTicker(w.Interval)
-	defer ticker.Stop()
+	done := make(chan struct{})
+	defer close(done)
+	w.runMu.Lock()
+	w.runDone = done
+	w.runMu.Unlock()
+
+	var poll <-chan time.Time
+	if w.PollInterval > 0 {
+		ticker := time.NewTicker(w.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
 
-	fmt.Println("CheckpointWorker started. Interval:", w.Interval)
+	var latency *time.Timer
+	var deadline <-chan time.Time
+	defer func() {
+		if latency != nil {
+			latency.Stop()
+		}
+	}()
+
+	logger := w.logger()
+	logger.Info("checkpoint worker started", logging.BatchSize(w.batchSize()),
+		slog.Duration("max_latency", w.maxLatency()), slog.Duration("poll_interval", w.PollInterval),
+		slog.Bool("adaptive", w.Adaptive != nil))
 
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("CheckpointWorker stopped.")
+			logger.Info("checkpoint worker stopped")
+			return
+		case <-w.stop:
+			logger.Info("checkpoint worker stopping")
+			return
+		case <-w.arrived:
+			if deadline == nil {
+				latency = time.NewTimer(w.maxLatency())
+				deadline = latency.C
+			}
+			continue
+		case <-w.flush:
+		case <-deadline:
+		case <-poll:
+		}
+
+		if latency != nil {
+			latency.Stop()
+			latency, deadline = nil, nil
+		}
+		w.drain(ctx)
+	}
+}
+
+// drain: 알림 수를 비우고, 한 번에 BatchSize만큼 가져오는 동안 계속 반영합니다. (반영 중 들어온 로그는 다시 알림을 받음)
+// Stop이 요청되거나, 오류가 났거나, 한 건도 커밋하지 못한 배치가 나오면 멈추고 다음 깨어날 때 다시 시도합니다.
+func (w *CheckpointWorker) drain(ctx context.Context) {
+	w.notified.Store(0)
+	for ctx.Err() == nil {
+		limit := w.batchSize()
+		result := w.ProcessCheckpoint(ctx)
+		if result.Err != nil || result.Fetched < limit || result.Committed == 0 {
 			return
-		case <-ticker.C:
-			w.ProcessCheckpoint(ctx)
+		}
+		select {
+		case <-w.stop:
+			return
+		default:
 		}
 	}
 }
 
+// Flush: 지금 가져갈 수 있는 로그가 없을 때까지 동기적으로 반영하고 그 합계를 반환합니다. (테스트나 호출자가 일관성을 강제할 때 사용)
+// Run이 배치를 처리 중이면 그 배치가 끝난 뒤에 시작합니다. 재시도 대기 중인 로그는 남을 수 있고, ctx가 끝나면 ctx.Err()를 반환합니다.
+// 로그를 가져오지 못했거나 트랜잭션이 롤백되면 그 오류로 멈추고, 한 건도 커밋하지 못한 배치가 나오면 같은 로그를 다시 붙잡지 않도록 멈춥니다.
+func (w *CheckpointWorker) Flush(ctx context.Context) (CheckpointResult, error) {
+	var total CheckpointResult
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		limit := w.batchSize()
+		result := w.ProcessCheckpoint(ctx)
+		total.Fetched += result.Fetched
+		total.merge(result)
+		if result.Err != nil {
+			return total, result.Err
+		}
+		if result.Fetched < limit || result.Committed == 0 {
+			return total, ctx.Err()
+		}
+	}
+}
+
+// Stop: Run에 멈추라고 알리고 진행 중인 배치가 끝나기를 기다린 뒤, ctx가 끝날 때까지 남은 로그를 비우고 결과를 보고합니다.
+// Run이 시작되지 않았으면 바로 비우기 시작합니다. Stop 후에는 Run을 다시 시작할 수 없습니다.
+// 비우는 중 ctx가 아닌 이유로 실패하면 남은 수를 채운 보고와 함께 그 오류를 반환합니다.
+func (w *CheckpointWorker) Stop(ctx context.Context) (DrainReport, error) {
+	var report DrainReport
+	var flushErr error
+
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.runMu.Lock()
+	done := w.runDone
+	w.runMu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			report.TimedOut = true
+		}
+	}
+
+	if !report.TimedOut {
+		result, err := w.Flush(ctx)
+		report.CheckpointResult = result
+		if ctx.Err() != nil {
+			report.TimedOut = true
+		} else {
+			flushErr = err
+		}
+	}
+
+	// ctx가 끝났어도 남은 수는 알려야 하므로 취소되지 않는 context로 셈
+	remaining, err := w.BufferRepo.CountPending(context.WithoutCancel(ctx))
+	if err != nil {
+		return report, err
+	}
+	report.Remaining = remaining
+	w.logger().Info("checkpoint worker drained",
+		slog.Int("committed", report.Committed), slog.Int64("remaining", report.Remaining), slog.Bool("timed_out", report.TimedOut))
+	return report, flushErr
+}
+
 // ProcessCheckpoint: 버퍼에서 로그를 읽어와 DB에 반영하는 핵심 로직
-func (w *CheckpointWorker) ProcessCheckpoint(ctx context.Context) {
-	// 1. Pending 로그 조회
-	logs, err := w.BufferRepo.GetPendingLogs(ctx, w.BatchSize)
+func (w *CheckpointWorker) ProcessCheckpoint(ctx context.Context) CheckpointResult {
+	w.processing.Lock()
+	defer w.processing.Unlock()
+
+	var result CheckpointResult
+	begin := time.Now()
+
+	// 1. 처리할 로그를 lease로 가져옴 (다른 Worker가 가져간 로그는 제외)
+	logs, err := w.BufferRepo.ClaimBatch(ctx, w.WorkerID, w.LeaseDuration, w.batchSize())
 	if err != nil {
-		fmt.Println("Error getting pending logs:", err)
-		return
+		w.logger().Error("failed to claim pending logs", logging.Err(err))
+		w.Metrics.Observe("checkpoint_worker", "process_checkpoint", begin, err)
+		result.Err = fmt.Errorf("failed to claim pending logs: %w", err)
+		return result
 	}
+	result.Fetched = len(logs)
 	if len(logs) == 0 {
+		w.adapt(ctx, 0, 0)
+		return result
+	}
+
+	start := time.Now()
+	logger := w.logger()
+	logger.Debug("processing batch", logging.BatchSize(len(logs)))
+
+	// 2. 순차 모드면 배치 전체를, 병렬 모드면 레코드 키별 파티션을 각각 반영
+	touched := make(map[int64]struct{})
+	partitions := partitionLogs(logs, w.Parallelism)
+	if len(partitions) == 1 {
+		result.merge(w.applyPartition(ctx, partitions[0], touched))
+	} else {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, partition := range partitions {
+			wg.Add(1)
+			go func(partition []model.BufferLog) {
+				defer wg.Done()
+				partTouched := make(map[int64]struct{})
+				partResult := w.applyPartition(ctx, partition, partTouched)
+
+				mu.Lock()
+				defer mu.Unlock()
+				result.merge(partResult)
+				for restaurantID := range partTouched {
+					touched[restaurantID] = struct{}{}
+				}
+			}(partition)
+		}
+		wg.Wait()
+	}
+
+	applyLatency := time.Since(start)
+	level := slog.LevelInfo
+	if result.Failed > 0 {
+		level = slog.LevelWarn
+	}
+	logger.Log(ctx, level, "checkpoint batch processed",
+		logging.BatchSize(result.Fetched),
+		slog.Int("committed", result.Committed),
+		slog.Int("applied", result.Applied),
+		slog.Int("transactions", result.Transactions),
+		slog.Int("failed", result.Failed),
+		slog.Int("retried", result.Retried),
+		slog.Int("dead_lettered", result.DeadLettered),
+		slog.Int("rejected", result.Rejected),
+		slog.Int("deferred", result.Deferred),
+		logging.Duration(applyLatency),
+	)
+
+	// 결과별 수는 서로 겹치지 않게 기록 (rejected는 dead_lettered에서 뺌, rolled_back은 트랜잭션째 롤백된 로그)
+	w.Metrics.Observe("checkpoint_worker", "process_checkpoint", begin, result.Err)
+	w.Metrics.CheckpointBatch(result.Fetched, map[string]int{
+		"committed":     result.Committed,
+		"retried":       result.Retried,
+		"dead_lettered": result.DeadLettered - result.Rejected,
+		"rejected":      result.Rejected,
+		"deferred":      result.Deferred,
+		"rolled_back":   result.Failed - result.Retried - result.DeadLettered,
+	})
+
+	// 3. 선제적 캐시 갱신: 이번 배치로 평점이 바뀐 식당의 가중 평점을 다시 계산
+	w.refreshCaches(ctx, touched)
+
+	// 4. 적응형 조정: 이번 배치의 반영 시간과 남은 로그 수로 다음 배치 크기와 대기 시간을 결정
+	w.adapt(ctx, result.Fetched, applyLatency)
+
+	return result
+}
+
+// adapt: Adaptive가 있으면 배치 결과와 남은 로그 수를 알려 결정을 갱신합니다.
+func (w *CheckpointWorker) adapt(ctx context.Context, fetched int, applyLatency time.Duration) {
+	if w.Adaptive == nil {
+		return
+	}
+	backlog, err := w.BufferRepo.CountPending(ctx)
+	if err != nil {
+		w.logger().Error("failed to count pending logs", logging.Err(err))
 		return
 	}
+	before := w.Adaptive.Decision()
+	after := w.Adaptive.Observe(fetched, applyLatency, backlog)
+	if after.BatchSize != before.BatchSize || after.Behind != before.Behind {
+		level := slog.LevelDebug
+		if after.Behind && !before.Behind {
+			level = slog.LevelWarn
+		}
+		w.logger().Log(ctx, level, "adaptive decision changed",
+			logging.BatchSize(after.BatchSize),
+			slog.Duration("max_latency", after.MaxLatency),
+			slog.Int64("backlog", after.Backlog),
+			slog.Duration("per_log", after.PerLog),
+			slog.Duration("estimated_freshness", after.EstimatedFreshness),
+			slog.Bool("behind", after.Behind),
+		)
+	}
+}
 
-	fmt.Printf("[Write] Processing %d logs...\n", len(logs))
+// applyPartition: 로그를 서브배치마다 트랜잭션 하나로 log_id 순서대로 반영합니다.
+// 어떤 레코드의 로그가 실패하면(또는 그 로그가 든 트랜잭션이 롤백되면) 같은 레코드의 뒤 로그는 반영하지 않고
+// claim을 풀어 돌려보내므로, 같은 레코드에 대한 변경은 절대 순서가 뒤바뀌지 않습니다.
+// 롤백된 서브배치의 로그는 모두 한 번 실패한 것으로 기록되어, 트랜잭션을 계속 깨뜨리는 로그도 결국 Dead Letter로 옮겨집니다.
+func (w *CheckpointWorker) applyPartition(ctx context.Context, logs []model.BufferLog, touched map[int64]struct{}) CheckpointResult {
+	var result CheckpointResult
+	blocked := make(map[recordKey]struct{})
 
-	var committedIDs []int64
+	for _, chunk := range splitBatch(logs, w.SubBatchSize) {
+		batch, err := w.applyBatch(ctx, chunk, blocked, touched)
+		if err != nil {
+			// 트랜잭션 전체가 롤백되었으므로 서브배치의 로그는 모두 PENDING으로 남음
+			w.logger().Error("failed to apply checkpoint batch", logging.BatchSize(len(chunk)), logging.Err(err))
+			failed := w.failChunk(ctx, chunk, err)
+			for _, log := range chunk {
+				if key, ok := keyOf(log); ok {
+					blocked[key] = struct{}{}
+				}
+			}
+			failed.Failed = len(chunk)
+			result.merge(failed)
+			if result.Err == nil {
+				result.Err = err
+			}
+			continue
+		}
+		result.merge(batch)
+		result.Transactions++
+	}
+
+	return result
+}
+
+// CoalescingRatio: 실제 쓰기 한 번이 평균 몇 개의 버퍼 로그를 반영했는지 (합치기가 없으면 1)
+func (r CheckpointResult) CoalescingRatio() float64 {
+	if r.Applied == 0 {
+		return 0
+	}
+	return float64(r.Committed) / float64(r.Applied)
+}
+
+// merge: 파티션/서브배치 결과를 합칩니다. (Fetched는 ProcessCheckpoint가 직접 설정)
+func (r *CheckpointResult) merge(other CheckpointResult) {
+	r.Committed += other.Committed
+	r.Applied += other.Applied
+	r.Failed += other.Failed
+	r.Retried += other.Retried
+	r.DeadLettered += other.DeadLettered
+	r.Rejected += other.Rejected
+	r.Deferred += other.Deferred
+	r.Transactions += other.Transactions
+	if r.Err == nil {
+		r.Err = other.Err
+	}
+}
 
-	// 2. 로그를 순회하며 실제 테이블에 반영 (COMMIT)
-	for _, log := range logs {
-		if err := w.processLog(ctx, log); err != nil {
-			fmt.Printf("Failed to process log ID %d: %v\n", log.LogID, err)
+// applyBatch: 로그들을 트랜잭션 하나로 반영하고 결과를 반환합니다. (err가 nil이 아니면 전부 롤백됨)
+// 로그마다 SAVEPOINT를 두어 실패한 로그의 부분 반영만 되돌리고 실패 기록을 남기며,
+// 나머지는 is_committed 갱신과 함께 커밋합니다. blocked에 든 레코드의 로그는 반영하지 않고 claim만 해제하며,
+// 실패한 로그의 레코드는 blocked에 추가됩니다. 커밋에 성공한 로그가 건드린 식당은 touched에 추가됩니다.
+func (w *CheckpointWorker) applyBatch(
+	ctx context.Context,
+	logs []model.BufferLog,
+	blocked map[recordKey]struct{},
+	touched map[int64]struct{},
+) (CheckpointResult, error) {
+	var result CheckpointResult
+
+	tx, err := w.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin checkpoint transaction: %w", err)
+	}
+	defer tx.Rollback() // Commit 이후에는 아무 일도 하지 않음
+
+	repos := repository.NewRepositories(tx)
+	var committedIDs, deferredIDs []int64
+	var committedLogs []model.BufferLog
+
+	for _, unit := range w.writeUnits(logs) {
+		key, keyed := keyOf(unit.log)
+		if _, ok := blocked[key]; keyed && ok {
+			for _, source := range unit.sources {
+				deferredIDs = append(deferredIDs, source.LogID)
+			}
 			continue
 		}
-		committedIDs = append(committedIDs, log.LogID)
+
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+logSavepoint); err != nil {
+			return result, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		if processErr := w.Registry.Apply(ctx, repos, unit.log); processErr != nil {
+			w.logger().Warn("failed to apply log", logging.LogID(unit.log.LogID),
+				slog.String("target_table", unit.log.TargetTable), logging.Err(processErr))
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO "+logSavepoint); err != nil {
+				return result, fmt.Errorf("failed to roll back log ID %d: %w", unit.log.LogID, err)
+			}
+			// 합쳐진 쓰기가 실패하면 원본 로그 모두 실패로 기록 (각자의 시도 횟수로 재시도/Dead Letter 판단)
+			rejected := buffer.IsRejection(processErr)
+			for _, source := range unit.sources {
+				deadLettered, err := w.recordFailure(ctx, repos.Buffer, source, processErr, rejected)
+				if err != nil {
+					return result, err
+				}
+				if deadLettered {
+					result.DeadLettered++
+				} else {
+					result.Retried++
+				}
+			}
+			if rejected {
+				result.Rejected += len(unit.sources)
+			}
+			if keyed {
+				blocked[key] = struct{}{}
+			}
+		} else {
+			for _, source := range unit.sources {
+				committedIDs = append(committedIDs, source.LogID)
+				committedLogs = append(committedLogs, source)
+			}
+			result.Applied++
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE "+logSavepoint); err != nil {
+			return result, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	// 반영 성공한 로그의 상태 업데이트 (같은 트랜잭션 안에서, lease를 빼앗겼으면 전체 롤백)
+	if err := repos.Buffer.CommitClaimed(ctx, w.WorkerID, committedIDs); err != nil {
+		return result, err
+	}
+	if err := repos.Buffer.ReleaseClaims(ctx, w.WorkerID, deferredIDs); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit checkpoint transaction: %w", err)
 	}
 
-	// 3. 반영 성공한 로그의 상태 업데이트
-	if len(committedIDs) > 0 {
-		if err := w.BufferRepo.UpdateCommitted(ctx, committedIDs); err != nil {
-			fmt.Println("Error updating committed status:", err)
+	for _, log := range committedLogs {
+		if restaurantID, ok := w.Registry.RestaurantRef(log); ok {
+			touched[restaurantID] = struct{}{}
 		}
-		fmt.Printf("[Write] Successfully committed and marked %d logs.\n", len(committedIDs))
 	}
+	result.Committed = len(committedIDs)
+	result.Failed = result.Retried + result.DeadLettered
+	result.Deferred = len(deferredIDs)
+	return result, nil
 }
 
-// processLog: 단일 로그를 해석하여 적절한 Repository 메소드를 호출합니다.
-func (w *CheckpointWorker) processLog(ctx context.Context, log model.BufferLog) error {
-	switch log.TargetTable {
-	case "User":
-		// User 업데이트 페이로드를 해석
-		var payload struct {
-			UserID         int64   `json:"user_id"`
-			NewScore       float64 `json:"new_score"`
-			NewReviewCount int64   `json:"new_review_count"`
-			NewBiasCount   int64   `json:"new_bias_count"`
+// writeUnits: Coalesce가 켜져 있으면 로그를 합치고, 아니면 로그 하나를 쓰기 하나로 반영합니다.
+func (w *CheckpointWorker) writeUnits(logs []model.BufferLog) []writeUnit {
+	if w.Coalesce {
+		return coalesceLogs(logs, w.Registry)
+	}
+
+	units := make([]writeUnit, len(logs))
+	for i, log := range logs {
+		units[i] = writeUnit{log: log, sources: []model.BufferLog{log}}
+	}
+	return units
+}
+
+// recordFailure: 실패한 로그의 시도 횟수를 올리고 지수 백오프로 다음 재시도 시각을 정합니다.
+// 최대 시도 횟수에 도달했거나 거절된(rejected) 로그면 Dead Letter로 옮기고 true를 반환합니다.
+func (w *CheckpointWorker) recordFailure(ctx context.Context, bufferRepo repository.BufferRepository, log model.BufferLog, cause error, rejected bool) (bool, error) {
+	attempts := log.AttemptCount + 1
+	nextRetryAt := time.Now().Add(w.Retry.Backoff(attempts))
+
+	if err := bufferRepo.RecordFailure(ctx, log.LogID, cause.Error(), nextRetryAt); err != nil {
+		return false, err
+	}
+	if !rejected && !w.Retry.Exhausted(attempts) {
+		return false, nil
+	}
+
+	if err := bufferRepo.MoveToDeadLetter(ctx, log.LogID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// failChunk: 롤백된 서브배치의 로그마다 실패를 기록하고(재시도 대기 또는 Dead Letter) 그 수를 반환합니다.
+// lease를 빼앗겼거나 ctx가 끝난 경우는 로그 탓이 아니므로 시도 횟수를 올리지 않고 claim만 해제합니다.
+func (w *CheckpointWorker) failChunk(ctx context.Context, logs []model.BufferLog, cause error) CheckpointResult {
+	var result CheckpointResult
+	if errors.Is(cause, repository.ErrLeaseLost) || ctx.Err() != nil {
+		w.releaseClaims(ctx, logs)
+		return result
+	}
+
+	for i, log := range logs {
+		deadLettered, err := w.recordFailure(ctx, w.BufferRepo, log, cause, false)
+		if err != nil {
+			// 기록하지 못한 로그는 lease 만료를 기다리지 않고 바로 다시 가져갈 수 있도록 claim을 해제
+			w.logger().Error("failed to record batch failure", logging.LogID(log.LogID), logging.Err(err))
+			w.releaseClaims(ctx, logs[i:])
+			return result
 		}
-		// Go 1.22+에서는 encoding/json의 Unmarshal이 json.RawMessage 대신 string을 허용합니다.
-		if err := json.Unmarshal([]byte(log.Payload), &payload); err != nil {
-			return fmt.Errorf("failed to unmarshal User payload: %w", err)
+		if deadLettered {
+			result.DeadLettered++
+		} else {
+			result.Retried++
 		}
+	}
+	return result
+}
 
-		// UserRepo.UpdateReliabilityScore 호출 (실제 테이블 반영)
-		return w.UserRepo.UpdateReliabilityScore(
-			ctx,
-			payload.UserID,
-			payload.NewScore,
-			payload.NewReviewCount,
-			payload.NewBiasCount,
-		)
+// releaseClaims: 반영하지 못한 로그의 claim을 해제합니다.
+func (w *CheckpointWorker) releaseClaims(ctx context.Context, logs []model.BufferLog) {
+	logIDs := make([]int64, len(logs))
+	for i, log := range logs {
+		logIDs[i] = log.LogID
+	}
+	if err := w.BufferRepo.ReleaseClaims(ctx, w.WorkerID, logIDs); err != nil {
+		w.logger().Error("failed to release claims", logging.BatchSize(len(logIDs)), logging.Err(err))
+	}
+}
 
-	default:
-		return fmt.Errorf("unsupported target table: %s", log.TargetTable)
+// splitBatch: 로그를 size개씩 나눕니다. (size가 0 이하이면 나누지 않음)
+func splitBatch(logs []model.BufferLog, size int) [][]model.BufferLog {
+	if size <= 0 || size >= len(logs) {
+		return [][]model.BufferLog{logs}
+	}
+
+	var chunks [][]model.BufferLog
+	for start := 0; start < len(logs); start += size {
+		end := start + size
+		if end > len(logs) {
+			end = len(logs)
+		}
+		chunks = append(chunks, logs[start:end])
+	}
+	return chunks
+}
+
+// refreshCaches: 배치가 건드린 식당마다 Cache_Metadata를 다시 계산합니다.
+func (w *CheckpointWorker) refreshCaches(ctx context.Context, touched map[int64]struct{}) {
+	if w.Aggregator == nil || len(touched) == 0 {
+		return
+	}
+
+	logger := w.logger()
+	for restaurantID := range touched {
+		start := time.Now()
+		if _, err := w.Aggregator.Refresh(ctx, restaurantID); err != nil {
+			logger.Error("failed to refresh cache", logging.RestaurantID(restaurantID), logging.Err(err))
+			continue
+		}
+		logger.Debug("cache refreshed", logging.RestaurantID(restaurantID), logging.Duration(time.Since(start)))
 	}
 }
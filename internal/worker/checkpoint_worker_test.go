@@ -0,0 +1,783 @@
+package worker_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+	"restaurant_db/internal/worker"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	return conn
+}
+
+// TestProcessCheckpointMaterializesReview: 버퍼에 쌓인 Review INSERT 로그가 Review 테이블에 반영되고 커밋 처리되는지 확인합니다.
+func TestProcessCheckpointMaterializesReview(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	bufferRepo := repository.NewBufferRepository(conn)
+	reviewRepo := repository.NewReviewRepository(conn)
+	analysisRepo := repository.NewAnalysisLogRepository(conn)
+	restaurantRepo := repository.NewRestaurantRepository(conn)
+	cacheRepo := repository.NewCacheRepository(conn)
+	aggregator := cache.NewRatingAggregator(restaurantRepo, reviewRepo, cacheRepo)
+	w := worker.NewCheckpointWorker(conn, aggregator, 10, time.Minute, nil)
+
+	// 1. Given: 식당 3과 그 식당에 대한 Review INSERT 로그
+	restaurant := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+	if err := restaurantRepo.Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if _, err := conn.Exec("UPDATE Restaurant SET restaurant_id = 3"); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	log := model.BufferLog{
+		TransactionType: "INSERT",
+		TargetTable:     "Review",
+		Payload:         `{"restaurant_id": 3, "user_id": 1, "rating": 5, "review_content": "최고", "reliability_weight": 0.7}`,
+	}
+	if err := bufferRepo.AddLog(ctx, &log); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 2. When: 체크포인트 실행
+	result := w.ProcessCheckpoint(ctx)
+	if result.Committed != 1 || result.Transactions != 1 {
+		t.Errorf("Expected 1 log committed in 1 transaction, got %+v", result)
+	}
+
+	// 3. Then: 리뷰가 생성되고 버퍼가 비어 있음
+	reviews, err := reviewRepo.FindByRestaurant(ctx, 3, 10, 0)
+	if err != nil {
+		t.Fatalf("FindByRestaurant failed: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].ReliabilityWeight != 0.7 || reviews[0].ReviewContent != "최고" {
+		t.Fatalf("Expected materialized review, got %+v", reviews)
+	}
+
+	// 극단적 평점(5점) 리뷰이므로 bias가 표시된 분석 요청이 남아야 함
+	analyses, err := analysisRepo.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPending failed: %v", err)
+	}
+	if len(analyses) != 1 || analyses[0].ReviewRefID != reviews[0].ReviewID || analyses[0].NewBiasCount != 1 {
+		t.Errorf("Expected one pending analysis log with bias, got %+v", analyses)
+	}
+
+	// 선제적 캐시 갱신: 식당 3의 캐시 행이 새 리뷰로 채워짐
+	cached, err := cacheRepo.FindCacheByID(ctx, 3)
+	if err != nil {
+		t.Fatalf("FindCacheByID failed: %v", err)
+	}
+	if cached == nil || cached.WeightedRating != 5 || cached.TotalWeightedReviews != 1 {
+		t.Errorf("Expected cache row refreshed by worker, got %+v", cached)
+	}
+
+	pending, err := bufferRepo.GetPendingLogs(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingLogs failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending logs, got %d", len(pending))
+	}
+}
+
+// TestWorkerRefreshSurvivesCachePolicy: Worker가 선제 갱신으로 새로 만든 캐시 행이 기본 정책의 바로 다음 Maintain에서
+// 내려가지 않고, 그 뒤에도 조회가 없으면 감쇠되어 내려가는지 확인합니다.
+func TestWorkerRefreshSurvivesCachePolicy(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	restaurantRepo := repository.NewRestaurantRepository(conn)
+	cacheRepo := repository.NewCacheRepository(conn)
+	config := cache.DefaultPolicyConfig()
+	aggregator := cache.NewRatingAggregator(restaurantRepo, repository.NewReviewRepository(conn), cacheRepo)
+	aggregator.InitialScore = config.InitialScore
+	policy := cache.NewPolicy(cacheRepo, restaurantRepo, aggregator, config, nil)
+	w := worker.NewCheckpointWorker(conn, aggregator, 10, time.Minute, nil)
+
+	// 1. Given: 캐시 행이 없는 식당에 대한 리뷰를 Worker가 반영해 캐시 행을 새로 만듦
+	restaurant := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+	if err := restaurantRepo.Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	log := model.BufferLog{TransactionType: "INSERT", TargetTable: "Review",
+		Payload: fmt.Sprintf(`{"restaurant_id": %d, "user_id": 1, "rating": 4, "review_content": "good", "reliability_weight": 1}`, restaurant.RestaurantID)}
+	if err := repository.NewBufferRepository(conn).AddLog(ctx, &log); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+	if result := w.ProcessCheckpoint(ctx); result.Committed != 1 {
+		t.Fatalf("Expected the review to commit, got %+v", result)
+	}
+
+	// 2. When & Then: 바로 다음 Maintain에서는 남음
+	if _, err := policy.Maintain(ctx); err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, restaurant.RestaurantID); cached == nil || cached.TotalWeightedReviews != 1 {
+		t.Fatalf("Expected the worker-refreshed row to survive the next Maintain, got %+v", cached)
+	}
+
+	// 3. When & Then: 조회 없이 한 주기가 더 지나면 내려감
+	if stats, err := policy.Maintain(ctx); err != nil || stats.Evicted != 1 {
+		t.Errorf("Expected the unread row to be evicted on the following cycle, got %+v (%v)", stats, err)
+	}
+}
+
+// TestProcessCheckpointIsAtomic: 실패한 로그는 부분 반영 없이 PENDING으로 남고,
+// is_committed 갱신이 실패하면 같은 트랜잭션의 반영도 모두 롤백되는지 확인합니다.
+func TestProcessCheckpointIsAtomic(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	bufferRepo := repository.NewBufferRepository(conn)
+	reviewRepo := repository.NewReviewRepository(conn)
+	w := worker.NewCheckpointWorker(conn, nil, 10, time.Minute, nil)
+	w.SubBatchSize = 2
+	w.Retry = worker.RetryPolicy{MaxAttempts: 5} // 대기 없이 바로 재시도
+
+	// 1. Given: 정상 리뷰 2건 + 해석할 수 없는 로그 1건 (서브배치 2개)
+	for _, payload := range []string{
+		`{"restaurant_id": 1, "user_id": 1, "rating": 4, "review_content": "a", "reliability_weight": 0.5}`,
+		`{"restaurant_id": 1, "user_id": 1, "rating": 3, "review_content": "b", "reliability_weight": 0.5}`,
+		`not json`,
+	} {
+		log := model.BufferLog{TransactionType: "INSERT", TargetTable: "Review", Payload: payload}
+		if err := bufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+
+	// 2. When: is_committed 갱신이 항상 실패하도록 만든 뒤 체크포인트 실행
+	if _, err := conn.Exec(`
+		CREATE TRIGGER fail_commit BEFORE UPDATE OF is_committed ON Buffer_Log
+		BEGIN SELECT RAISE(ABORT, 'commit marker unavailable'); END`); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	result := w.ProcessCheckpoint(ctx)
+
+	// 3. Then: 리뷰가 하나도 반영되지 않음 (로그 반영과 커밋 표시가 함께 롤백)
+	if result.Committed != 0 || result.Failed != 3 {
+		t.Errorf("Expected nothing committed, got %+v", result)
+	}
+	if reviews, _ := reviewRepo.FindByRestaurant(ctx, 1, 10, 0); len(reviews) != 0 {
+		t.Fatalf("Expected no reviews after rolled back batch, got %d", len(reviews))
+	}
+
+	// 4. When: 트리거를 제거하고 다시 실행
+	if _, err := conn.Exec(`DROP TRIGGER fail_commit`); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	result = w.ProcessCheckpoint(ctx)
+
+	// 5. Then: 정상 로그 2건만 반영되고 잘못된 로그는 PENDING으로 남음
+	if result.Committed != 2 || result.Failed != 1 || result.Transactions != 2 {
+		t.Errorf("Expected 2 committed, 1 failed in 2 transactions, got %+v", result)
+	}
+	if reviews, _ := reviewRepo.FindByRestaurant(ctx, 1, 10, 0); len(reviews) != 2 {
+		t.Errorf("Expected 2 reviews, got %d", len(reviews))
+	}
+	pending, err := bufferRepo.GetPendingLogs(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingLogs failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Payload != "not json" || pending[0].AttemptCount != 2 {
+		t.Errorf("Expected only the malformed log to remain pending after 2 attempts, got %+v", pending)
+	}
+}
+
+// TestProcessCheckpointDeadLetters: 최대 시도 횟수만큼 실패한 로그가 Dead Letter로 옮겨지고,
+// 운영자가 페이로드를 고쳐 재투입하면 정상 반영되는지 확인합니다.
+func TestProcessCheckpointDeadLetters(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	bufferRepo := repository.NewBufferRepository(conn)
+	deadLetterRepo := repository.NewDeadLetterRepository(conn)
+	w := worker.NewCheckpointWorker(conn, nil, 10, time.Minute, nil)
+	w.Retry = worker.RetryPolicy{MaxAttempts: 2}
+
+	// 1. Given: 해석할 수 없는 Review 로그
+	poison := model.BufferLog{TransactionType: "INSERT", TargetTable: "Review", Payload: `{"rating": "five"}`}
+	if err := bufferRepo.AddLog(ctx, &poison); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 처리할 핸들러가 없는 로그는 재시도 없이 바로 거절
+	unknown := model.BufferLog{TransactionType: "INSERT", TargetTable: "Location", Payload: `{}`}
+	if err := bufferRepo.AddLog(ctx, &unknown); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 2. When: 두 번 실패할 때까지 체크포인트 실행
+	if result := w.ProcessCheckpoint(ctx); result.Retried != 1 || result.Rejected != 1 || result.DeadLettered != 1 {
+		t.Fatalf("Expected first failure to be retried and unknown route rejected, got %+v", result)
+	}
+	if result := w.ProcessCheckpoint(ctx); result.DeadLettered != 1 {
+		t.Fatalf("Expected second failure to dead-letter, got %+v", result)
+	}
+
+	// 3. Then: 버퍼는 비고 Dead Letter에 시도 횟수와 오류가 남음
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 10); len(pending) != 0 {
+		t.Errorf("Expected dead-lettered log to leave the buffer, got %+v", pending)
+	}
+	deadLetters, err := deadLetterRepo.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(deadLetters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(deadLetters))
+	}
+	deadLetter := deadLetters[0] // 최근에 실패한 순
+	if deadLetter.OriginalLogID != poison.LogID {
+		deadLetter = deadLetters[1]
+	}
+	if deadLetter.OriginalLogID != poison.LogID || deadLetter.AttemptCount != 2 || deadLetter.LastError == "" {
+		t.Errorf("Expected dead letter to keep log ID, attempts and error, got %+v", deadLetter)
+	}
+
+	// 4. When: 페이로드를 고쳐 재투입
+	deadLetter.Payload = `{"restaurant_id": 1, "user_id": 1, "rating": 4, "review_content": "fixed", "reliability_weight": 1}`
+	if err := deadLetterRepo.Update(ctx, &deadLetter); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	logID, err := deadLetterRepo.Requeue(ctx, deadLetter.DeadLetterID)
+	if err != nil {
+		t.Fatalf("Requeue failed: %v", err)
+	}
+	if found, _ := deadLetterRepo.FindByID(ctx, deadLetter.DeadLetterID); found != nil {
+		t.Errorf("Expected requeued dead letter to be removed, got %+v", found)
+	}
+
+	// 5. Then: 새 로그로 반영됨
+	if result := w.ProcessCheckpoint(ctx); result.Committed != 1 || logID == 0 {
+		t.Errorf("Expected requeued log %d to commit, got %+v", logID, result)
+	}
+	if reviews, _ := repository.NewReviewRepository(conn).FindByRestaurant(ctx, 1, 10, 0); len(reviews) != 1 {
+		t.Errorf("Expected requeued review to be materialized, got %d", len(reviews))
+	}
+}
+
+// countingNotifier: LogAdded 호출을 세는 LogNotifier
+type countingNotifier struct {
+	mu   sync.Mutex
+	logs []model.BufferLog
+}
+
+func (n *countingNotifier) LogAdded(log model.BufferLog) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logs = append(n.logs, log)
+}
+
+// TestRequeueDeadLetterValidatesAndKeepsOrder: 수정한 Dead Letter가 검증기를 거치고,
+// 같은 유저에 더 새로운 로그가 있으면 재투입이 거절되며, 재투입한 로그는 Worker에 알려지는지 확인합니다.
+func TestRequeueDeadLetterValidatesAndKeepsOrder(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	bufferRepo := repository.NewBufferRepository(conn)
+	notifier := &countingNotifier{}
+	deadLetterRepo := repository.NewDeadLetterRepository(conn,
+		repository.WithValidator(buffer.DefaultRegistry()), repository.WithNotifier(notifier))
+
+	// 1. Given: 두 유저의 잘못된 User 로그가 Dead Letter로 옮겨지고, 첫 유저에는 그 뒤에 새 로그가 쌓임
+	deadLetters := make(map[int64]model.DeadLetter)
+	for _, userID := range []int64{1, 2} {
+		bad := model.BufferLog{TransactionType: model.TransactionUpdate, TargetTable: "User", TargetRecordID: userID,
+			Payload: fmt.Sprintf(`{"mode": "set", "user_id": %d, "new_score": 1.5}`, userID)}
+		if err := bufferRepo.AddLog(ctx, &bad); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+		if err := bufferRepo.MoveToDeadLetter(ctx, bad.LogID); err != nil {
+			t.Fatalf("MoveToDeadLetter failed: %v", err)
+		}
+	}
+	list, err := deadLetterRepo.List(ctx, 10, 0)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d (%v)", len(list), err)
+	}
+	for _, deadLetter := range list {
+		deadLetters[deadLetter.TargetRecordID] = deadLetter
+	}
+	newer, err := buffer.NewUserSetLog(1, 0.8, 3, 0)
+	if err != nil {
+		t.Fatalf("NewUserSetLog failed: %v", err)
+	}
+	if err := bufferRepo.AddLog(ctx, &newer); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	// 2. When & Then: 여전히 잘못된 수정은 저장되지 않음
+	invalid := deadLetters[2]
+	invalid.Payload = `{"mode": "set", "user_id": 2, "new_scor": 0.5}`
+	if err := deadLetterRepo.Update(ctx, &invalid); !errors.Is(err, repository.ErrInvalidLog) {
+		t.Errorf("Expected invalid edit to be refused, got %v", err)
+	}
+
+	// 3. When: 두 Dead Letter를 고쳐 재투입
+	for userID, deadLetter := range deadLetters {
+		deadLetter.Payload = fmt.Sprintf(`{"mode": "set", "user_id": %d, "new_score": 0.5}`, userID)
+		if err := deadLetterRepo.Update(ctx, &deadLetter); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+	_, staleErr := deadLetterRepo.Requeue(ctx, deadLetters[1].DeadLetterID)
+	logID, err := deadLetterRepo.Requeue(ctx, deadLetters[2].DeadLetterID)
+	if err != nil {
+		t.Fatalf("Requeue failed: %v", err)
+	}
+
+	// 4. Then: 새 로그가 있는 유저는 거절되어 Dead Letter로 남고, 다른 유저는 현재 버전으로 재투입되어 알려짐
+	if !errors.Is(staleErr, repository.ErrNewerLogsExist) {
+		t.Errorf("Expected requeue behind a newer log to be refused, got %v", staleErr)
+	}
+	if found, _ := deadLetterRepo.FindByID(ctx, deadLetters[1].DeadLetterID); found == nil {
+		t.Error("Expected refused dead letter to remain")
+	}
+	pending, err := bufferRepo.GetPendingForRecord(ctx, "User", 2)
+	if err != nil || len(pending) != 1 || pending[0].LogID != logID || pending[0].PayloadVersion != buffer.UserPayloadVersion {
+		t.Errorf("Expected requeued log %d at the current version, got %+v (%v)", logID, pending, err)
+	}
+	if len(notifier.logs) != 1 || notifier.logs[0].LogID != logID {
+		t.Errorf("Expected one notification for log %d, got %+v", logID, notifier.logs)
+	}
+}
+
+// TestFlushCountsRolledBackTransactions: 트랜잭션째 롤백되는 로그가 있으면 Flush가 그 오류를 반환하고,
+// 롤백도 시도 횟수에 포함되어 결국 Dead Letter로 옮겨지는지 확인합니다.
+func TestFlushCountsRolledBackTransactions(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: 반영 중 Worker의 SAVEPOINT를 먼저 풀어 트랜잭션을 깨뜨리는 핸들러와 그 로그
+	registry := buffer.NewRegistry()
+	buffer.Register(registry, "Poison", model.TransactionInsert,
+		func(ctx context.Context, repos *repository.Repositories, log model.BufferLog, payload struct{}) error {
+			_, err := repos.Buffer.(*repository.BufferRepoImpl).DB.ExecContext(ctx, "RELEASE checkpoint_log")
+			return err
+		})
+	bufferRepo := repository.NewBufferRepository(conn)
+	poison := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Poison", Payload: `{}`}
+	if err := bufferRepo.AddLog(ctx, &poison); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	w := worker.NewCheckpointWorker(conn, nil, 10, time.Minute, nil)
+	w.Registry = registry
+	w.Retry = worker.RetryPolicy{MaxAttempts: 2}
+
+	// 2. When & Then: 첫 Flush는 오류를 반환하고 로그는 재시도 대기로 남음
+	result, err := w.Flush(ctx)
+	if err == nil || result.Retried != 1 || result.Committed != 0 {
+		t.Fatalf("Expected the rolled-back transaction to be reported and retried, got %+v (%v)", result, err)
+	}
+	pending, _ := bufferRepo.GetPendingLogs(ctx, 10)
+	if len(pending) != 1 || pending[0].AttemptCount != 1 {
+		t.Fatalf("Expected one pending log with 1 attempt, got %+v", pending)
+	}
+
+	// 3. When & Then: 두 번째 실패에서 Dead Letter로 옮겨짐
+	if result, err := w.Flush(ctx); err == nil || result.DeadLettered != 1 {
+		t.Fatalf("Expected the second rollback to dead-letter the log, got %+v (%v)", result, err)
+	}
+	if count, _ := repository.NewDeadLetterRepository(conn).Count(ctx); count != 1 {
+		t.Errorf("Expected 1 dead letter, got %d", count)
+	}
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 10); len(pending) != 0 {
+		t.Errorf("Expected the buffer to be empty, got %+v", pending)
+	}
+}
+
+// TestRetryPolicyBackoff: 지수 백오프와 상한, 포기 기준을 확인합니다. (상한이 없으면 시도 횟수가 커도 넘치지 않아야 함)
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := worker.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	unbounded := worker.RetryPolicy{BaseDelay: time.Second}
+
+	tests := []struct {
+		name     string
+		policy   worker.RetryPolicy
+		attempts int64
+		want     time.Duration
+	}{
+		{"first failure", policy, 1, time.Second},
+		{"doubles", policy, 2, 2 * time.Second},
+		{"doubles again", policy, 3, 4 * time.Second},
+		{"capped by max delay", policy, 10, 5 * time.Second},
+		{"capped after many attempts", policy, math.MaxInt64, 5 * time.Second},
+		{"unbounded doubles", unbounded, 11, 1024 * time.Second},
+		{"unbounded saturates", unbounded, 64, math.MaxInt64},
+		{"unbounded after many attempts", unbounded, math.MaxInt64, math.MaxInt64},
+		{"no base delay", worker.RetryPolicy{}, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("%s: Backoff(%d) expected %s, got %s", tt.name, tt.attempts, tt.want, got)
+		}
+	}
+	if policy.Exhausted(2) || !policy.Exhausted(3) {
+		t.Errorf("Expected policy to give up at exactly 3 attempts")
+	}
+}
+
+// TestConcurrentWorkersApplyEachLogOnce: 파일 DB에서 여러 Worker가 동시에 버퍼를 비워도
+// 각 로그가 정확히 한 번만 반영되는지 확인합니다.
+func TestConcurrentWorkersApplyEachLogOnce(t *testing.T) {
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "buffer.db"))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: Review INSERT 로그 200건
+	const logCount = 200
+	bufferRepo := repository.NewBufferRepository(conn)
+	for i := 0; i < logCount; i++ {
+		log := model.BufferLog{
+			TransactionType: "INSERT",
+			TargetTable:     "Review",
+			Payload:         fmt.Sprintf(`{"restaurant_id": 1, "user_id": 1, "rating": 3, "review_content": "r%d", "reliability_weight": 1}`, i),
+		}
+		if err := bufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+
+	// 2. When: Worker 4개가 각자 버퍼가 빌 때까지 작은 배치로 처리
+	var wg sync.WaitGroup
+	committed := make([]int, 4)
+	for i := range committed {
+		w := worker.NewCheckpointWorker(conn, nil, 7, time.Minute, nil)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				result := w.ProcessCheckpoint(ctx)
+				committed[i] += result.Committed
+				if result.Fetched == 0 {
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 3. Then: 모든 로그가 한 번씩만 반영됨
+	var total, workersUsed int
+	for _, n := range committed {
+		total += n
+		if n > 0 {
+			workersUsed++
+		}
+	}
+	if total != logCount {
+		t.Errorf("Expected %d logs committed in total, got %d (%v)", logCount, total, committed)
+	}
+	var reviews int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Review").Scan(&reviews); err != nil {
+		t.Fatalf("Failed to count reviews: %v", err)
+	}
+	if reviews != logCount {
+		t.Errorf("Expected %d reviews (no duplicates), got %d", logCount, reviews)
+	}
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 10); len(pending) != 0 {
+		t.Errorf("Expected empty buffer, got %d pending", len(pending))
+	}
+	t.Logf("logs per worker: %v (%d workers participated)", committed, workersUsed)
+}
+
+// TestParallelPartitionsKeepPerUserOrder: 병렬 모드에서도 유저별 업데이트가 log_id 순서대로 반영되고,
+// 앞선 로그가 실패한 유저의 뒤 로그는 반영되지 않고 돌려보내지는지 확인합니다.
+func TestParallelPartitionsKeepPerUserOrder(t *testing.T) {
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "buffer.db"))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: 유저 5명에게 review_count를 1..20으로 바꾸는 로그를 번갈아 기록 (유저 3의 10번째 로그는 깨짐)
+	const users, updates = 5, 20
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	for i := 0; i < users; i++ {
+		if err := userRepo.Create(ctx, &model.User{Username: fmt.Sprintf("user%d", i)}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	for n := 1; n <= updates; n++ {
+		for userID := int64(1); userID <= users; userID++ {
+			payload := fmt.Sprintf(`{"mode": "set", "user_id": %d, "new_score": 0.5, "new_review_count": %d, "new_bias_count": 0}`, userID, n)
+			if userID == 3 && n == 10 {
+				payload = `{"user_id": "broken"}`
+			}
+			log := model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", Payload: payload, TargetRecordID: userID}
+			if err := bufferRepo.AddLog(ctx, &log); err != nil {
+				t.Fatalf("AddLog failed: %v", err)
+			}
+		}
+	}
+
+	// 2. When: 파티션 4개로 병렬 반영
+	w := worker.NewCheckpointWorker(conn, nil, users*updates, time.Minute, nil)
+	w.Parallelism = 4
+	w.SubBatchSize = 8
+	result := w.ProcessCheckpoint(ctx)
+
+	// 3. Then: 유저 3은 9번째 업데이트에서 멈추고 뒤 10개는 돌려보내지며, 나머지는 마지막 값(20)이 반영됨
+	if result.Retried != 1 || result.Deferred != updates-10 || result.Committed != users*updates-(updates-9) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	for userID := int64(1); userID <= users; userID++ {
+		user, err := userRepo.FindByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("FindByID failed: %v", err)
+		}
+		want := int64(updates)
+		if userID == 3 {
+			want = 9
+		}
+		if user.ReviewCount != want {
+			t.Errorf("User %d: expected review_count %d, got %d", userID, want, user.ReviewCount)
+		}
+	}
+
+	// 돌려보낸 로그는 claim이 풀려 있지만, 재시도 대기 중인 로그 뒤에 있으므로 아직 가져갈 수 없음
+	if claimed, _ := bufferRepo.ClaimBatch(ctx, "other", time.Minute, 100); len(claimed) != 0 {
+		t.Errorf("Expected deferred logs to stay behind the retrying log, got %d", len(claimed))
+	}
+}
+
+// TestProcessCheckpointCoalescesUserUpdates: 같은 유저에 대한 set/delta 업데이트가 쓰기 하나로 합쳐지고
+// 원본 로그는 모두 커밋되는지 확인합니다.
+func TestProcessCheckpointCoalescesUserUpdates(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	for _, name := range []string{"alice", "bob"} {
+		if err := userRepo.Create(ctx, &model.User{Username: name}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	// 1. Given: alice는 set 5번 뒤 delta 3번, bob(기본 0.5점)은 delta 2번
+	type update struct {
+		userID  int64
+		payload string
+	}
+	var updates []update
+	for i := 1; i <= 5; i++ {
+		updates = append(updates, update{1, fmt.Sprintf(`{"mode": "set", "user_id": 1, "new_score": 0.%d, "new_review_count": %d, "new_bias_count": 1}`, i, i)})
+	}
+	for i := 0; i < 3; i++ {
+		updates = append(updates, update{1, `{"mode": "delta", "user_id": 1, "score_delta": 0.1, "review_count_delta": 1}`})
+	}
+	for i := 0; i < 2; i++ {
+		updates = append(updates, update{2, `{"mode": "delta", "user_id": 2, "score_delta": -0.1, "review_count_delta": 1, "bias_count_delta": 1}`})
+	}
+	for _, u := range updates {
+		log := model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", Payload: u.payload, TargetRecordID: u.userID}
+		if err := bufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+
+	// 2. When
+	w := worker.NewCheckpointWorker(conn, nil, 100, time.Minute, nil)
+	result := w.ProcessCheckpoint(ctx)
+
+	// 3. Then: 10개 로그가 쓰기 2번으로 반영
+	if result.Committed != 10 || result.Applied != 2 || result.CoalescingRatio() != 5 {
+		t.Errorf("Expected 10 logs in 2 writes (5x), got %+v", result)
+	}
+	alice, _ := userRepo.FindByID(ctx, 1)
+	if alice.ReviewCount != 8 || alice.BiasCount != 1 || alice.ReliabilityScore < 0.799 || alice.ReliabilityScore > 0.801 {
+		t.Errorf("Expected alice = last set + 3 deltas (0.8, 8, 1), got %+v", alice)
+	}
+	bob, _ := userRepo.FindByID(ctx, 2)
+	if bob.ReviewCount != 2 || bob.BiasCount != 2 || bob.ReliabilityScore < 0.299 || bob.ReliabilityScore > 0.301 {
+		t.Errorf("Expected bob = 0.5 - 0.2 with 2 reviews and 2 biases, got %+v", bob)
+	}
+	if pending, _ := bufferRepo.GetPendingLogs(ctx, 100); len(pending) != 0 {
+		t.Errorf("Expected every source log committed, got %d pending", len(pending))
+	}
+}
+
+// TestRunFlushesOnNotification: Run이 주기를 기다리지 않고 BatchSize 도달, RequestFlush, MaxLatency 경과 시 버퍼를 비우는지 확인합니다.
+func TestRunFlushesOnNotification(t *testing.T) {
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "buffer.db"))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+
+	// run: 주기 확인 없이 알림으로만 깨어나는 Worker를 띄우고, 그 Worker에 알리는 BufferRepository를 반환
+	run := func(maxLatency time.Duration) (repository.BufferRepository, *worker.CheckpointWorker, context.CancelFunc) {
+		w := worker.NewCheckpointWorker(conn, nil, 3, maxLatency, nil)
+		w.PollInterval = 0
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			w.Run(ctx)
+		}()
+		return repository.NewBufferRepository(conn, repository.WithNotifier(w)), w, func() { cancel(); <-done }
+	}
+	addLogs := func(repo repository.BufferRepository, n int) {
+		for i := 0; i < n; i++ {
+			log := model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", TargetRecordID: 1,
+				Payload: `{"mode": "set", "user_id": 1, "new_score": 0.5, "new_review_count": 1, "new_bias_count": 0}`}
+			if err := repo.AddLog(context.Background(), &log); err != nil {
+				t.Fatalf("AddLog failed: %v", err)
+			}
+		}
+	}
+	pendingCount := func(repo repository.BufferRepository) int {
+		logs, err := repo.GetPendingLogs(context.Background(), 100)
+		if err != nil {
+			t.Fatalf("GetPendingLogs failed: %v", err)
+		}
+		return len(logs)
+	}
+	waitEmpty := func(repo repository.BufferRepository, reason string) {
+		deadline := time.Now().Add(2 * time.Second)
+		for pendingCount(repo) > 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected buffer to be flushed on %s", reason)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// 1. Given: MaxLatency가 매우 긴 Worker
+	repo, w, stop := run(time.Hour)
+
+	// 2. When & 3. Then: BatchSize(3)보다 적으면 반영되지 않다가, 3번째 로그에서 바로 반영됨
+	addLogs(repo, 2)
+	time.Sleep(100 * time.Millisecond)
+	if n := pendingCount(repo); n != 2 {
+		t.Fatalf("Expected 2 logs to wait for the batch, got %d pending", n)
+	}
+	addLogs(repo, 1)
+	waitEmpty(repo, "batch size")
+
+	// RequestFlush는 조건을 기다리지 않고 반영
+	addLogs(repo, 1)
+	w.RequestFlush()
+	waitEmpty(repo, "RequestFlush")
+	stop()
+
+	// MaxLatency가 짧으면 로그 하나도 그 시간 안에 반영
+	repo, _, stop = run(50 * time.Millisecond)
+	addLogs(repo, 1)
+	waitEmpty(repo, "max latency")
+	stop()
+
+	// 알림이 쌓인 뒤 BatchSize가 그 수 아래로 줄어도(적응형 조정) 다음 알림에서 바로 반영
+	shrunk := worker.NewCheckpointWorker(conn, nil, 3, time.Hour, nil)
+	shrunk.PollInterval = 0
+	repo = repository.NewBufferRepository(conn, repository.WithNotifier(shrunk))
+	addLogs(repo, 2)
+	shrunk.BatchSize = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shrunk.Run(ctx)
+	}()
+	defer func() { cancel(); <-done }()
+	addLogs(repo, 1)
+	waitEmpty(repo, "batch size shrinking below the notified count")
+}
+
+func TestStopDrainsPendingLogs(t *testing.T) {
+	conn, err := db.InitDB(filepath.Join(t.TempDir(), "buffer.db"))
+	if err != nil {
+		t.Fatalf("could not initialize database: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	// 1. Given: 알림을 받아도 한참 뒤에야 깨어나는 Worker가 실행 중이고, BatchSize(2)보다 많은 로그가 쌓여 있음
+	w := worker.NewCheckpointWorker(conn, nil, 2, time.Hour, nil)
+	w.PollInterval = 0
+	bufferRepo := repository.NewBufferRepository(conn, repository.WithNotifier(w))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	addLog := func() {
+		log := model.BufferLog{TransactionType: "UPDATE", TargetTable: "User", TargetRecordID: 1,
+			Payload: `{"mode": "set", "user_id": 1, "new_score": 0.5, "new_review_count": 1, "new_bias_count": 0}`}
+		if err := bufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+	addLog()
+
+	// Flush는 기다리지 않고 바로 반영
+	result, err := w.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if result.Fetched != 1 {
+		t.Fatalf("Expected Flush to apply 1 log, got %+v", result)
+	}
+	for i := 0; i < 5; i++ {
+		addLog()
+	}
+
+	// 2. When: Stop
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	report, err := w.Stop(stopCtx)
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// 3. Then: Run이 반환되고, 남은 로그를 모두 반영했다고 보고
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return after Stop")
+	}
+	if report.TimedOut || report.Remaining != 0 {
+		t.Fatalf("Expected a complete drain, got %+v", report)
+	}
+	pending, err := bufferRepo.CountPending(ctx)
+	if err != nil {
+		t.Fatalf("CountPending failed: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("Expected no pending logs after Stop, got %d", pending)
+	}
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/model"
+)
+
+// writeUnit: 실제로 반영할 로그 하나와, 그 로그로 합쳐진 원본 로그들
+// 반영에 성공하면 sources가 모두 커밋되고, 실패하면 sources 모두에 실패가 기록됩니다.
+type writeUnit struct {
+	log     model.BufferLog
+	sources []model.BufferLog
+}
+
+// coalesceLogs: 같은 레코드에 대한 연속된 업데이트를 Registry에 등록된 합치기 규칙으로 하나의 쓰기로 합칩니다.
+// 합칠 수 없는 로그(합치기 규칙이 없는 Route, 해석할 수 없는 payload 등)는 그대로 두고, 같은 레코드의 합치기 구간을 끊습니다.
+// 서로 다른 레코드 사이의 순서는 결과에 영향을 주지 않으므로 각 쓰기는 첫 원본 로그의 위치에 놓입니다.
+func coalesceLogs(logs []model.BufferLog, registry *buffer.Registry) []writeUnit {
+	units := make([]writeUnit, 0, len(logs))
+	open := make(map[recordKey]int) // 레코드별로 아직 더 합칠 수 있는 units의 인덱스
+
+	for _, log := range logs {
+		key, keyed := keyOf(log)
+		if !keyed {
+			units = append(units, writeUnit{log: log, sources: []model.BufferLog{log}})
+			continue
+		}
+
+		if i, ok := open[key]; ok {
+			if merged, ok := registry.Merge(units[i].log, log); ok {
+				units[i].log = merged
+				units[i].sources = append(units[i].sources, log)
+				continue
+			}
+		}
+
+		units = append(units, writeUnit{log: log, sources: []model.BufferLog{log}})
+		if registry.Mergeable(log) {
+			open[key] = len(units) - 1
+		} else {
+			delete(open, key) // 합칠 수 없는 로그 뒤의 업데이트는 그 로그보다 먼저 반영되면 안 됨
+		}
+	}
+
+	return units
+}
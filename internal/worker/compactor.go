@@ -0,0 +1,222 @@
+package worker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+// 압축 기본 설정
+const (
+	DefaultBufferRetention = 7 * 24 * time.Hour // 반영된 로그를 남겨 두는 기간
+	DefaultCompactChunk    = 500                // 한 번에 지우는 최대 행 수
+)
+
+// Compactor는 반영이 끝난 지 오래된 Buffer_Log 행을 지워 테이블과 인덱스가 끝없이 커지지 않게 합니다.
+// ArchiveDir이 있으면 지우기 전에 gzip으로 압축한 NDJSON 파일(한 줄에 로그 하나)로 남겨 감사에 사용합니다.
+// 한 번에 ChunkSize 행씩 짧은 문장으로 지우므로 AddLog를 오래 막지 않습니다.
+type Compactor struct {
+	BufferRepo repository.BufferRepository
+	Retention  time.Duration
+	ChunkSize  int
+	ArchiveDir string // 비어 있으면 보관 파일 없이 지움
+	Interval   time.Duration
+	Logger     *slog.Logger
+}
+
+// CompactionResult: Compact 한 번의 결과
+type CompactionResult struct {
+	Deleted     int64  // 지운 Buffer_Log 행 수
+	Chunks      int    // 나눠서 실행한 횟수
+	KeysPurged  int64  // 지운 만료 멱등성 키 수
+	ArchivePath string // 보관 파일 경로 (보관한 행이 없으면 빈 문자열)
+}
+
+func NewCompactor(bufferRepo repository.BufferRepository, retention time.Duration, archiveDir string, interval time.Duration, logger *slog.Logger) *Compactor {
+	return &Compactor{
+		BufferRepo: bufferRepo,
+		Retention:  retention,
+		ChunkSize:  DefaultCompactChunk,
+		ArchiveDir: archiveDir,
+		Interval:   interval,
+		Logger:     logging.Component(logger, "compactor"),
+	}
+}
+
+// Run: Interval마다 Compact를 실행합니다.
+func (c *Compactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	c.Logger.Info("compactor started", slog.Duration("interval", c.Interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.Logger.Info("compactor stopped")
+			return
+		case <-ticker.C:
+			if _, err := c.Compact(ctx); err != nil {
+				c.Logger.Error("failed to compact buffer log", logging.Err(err))
+			}
+		}
+	}
+}
+
+// Compact: Retention보다 오래된 반영 완료 로그를 ChunkSize씩 (보관 후) 지우고, 만료된 멱등성 키를 정리합니다.
+// 중간에 실패하면 그때까지 지운 결과와 오류를 함께 반환합니다. 보관 파일에는 지우기 전에 쓰므로, 실패한 청크의 행이 파일에만 남을 수는 있어도 파일에서 빠지지는 않습니다.
+func (c *Compactor) Compact(ctx context.Context) (CompactionResult, error) {
+	var result CompactionResult
+	start := time.Now()
+	cutoff := start.Add(-c.Retention)
+
+	var archive *logArchive
+	defer func() {
+		if archive != nil {
+			archive.Close()
+		}
+	}()
+
+	for {
+		logs, err := c.BufferRepo.GetCommittedBefore(ctx, cutoff, c.ChunkSize)
+		if err != nil {
+			return result, err
+		}
+		if len(logs) == 0 {
+			break
+		}
+
+		if c.ArchiveDir != "" {
+			if archive == nil {
+				if archive, err = createLogArchive(c.ArchiveDir); err != nil {
+					return result, err
+				}
+				result.ArchivePath = archive.Path
+			}
+			if err := archive.Write(logs); err != nil {
+				return result, err
+			}
+		}
+
+		ids := make([]int64, len(logs))
+		for i, log := range logs {
+			ids[i] = log.LogID
+		}
+		deleted, err := c.BufferRepo.DeleteCommitted(ctx, ids)
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += deleted
+		result.Chunks++
+
+		if len(logs) < c.ChunkSize {
+			break
+		}
+	}
+
+	if archive != nil {
+		err := archive.Close()
+		archive = nil
+		if err != nil {
+			return result, err
+		}
+	}
+
+	purged, err := c.BufferRepo.PurgeExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.KeysPurged = purged
+
+	if result.Deleted > 0 || result.KeysPurged > 0 {
+		c.Logger.Info("buffer log compacted",
+			slog.Int64("deleted", result.Deleted),
+			slog.Int("chunks", result.Chunks),
+			slog.Int64("keys_purged", result.KeysPurged),
+			slog.String("archive_path", result.ArchivePath),
+			logging.Duration(time.Since(start)),
+		)
+	}
+	return result, nil
+}
+
+// archivedLog: 보관 파일 한 줄의 형태
+type archivedLog struct {
+	LogID           int64     `json:"log_id"`
+	TransactionType string    `json:"transaction_type"`
+	TargetTable     string    `json:"target_table"`
+	Payload         string    `json:"payload"`
+	PayloadVersion  int       `json:"payload_version"`
+	TargetRecordID  int64     `json:"target_record_id"`
+	LogUpdatedAt    time.Time `json:"log_updated_at"`
+	AttemptCount    int64     `json:"attempt_count"`
+}
+
+// logArchive: gzip으로 압축한 NDJSON 보관 파일
+type logArchive struct {
+	Path string
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// createLogArchive: dir 아래에 실행 시각이 들어간 새 보관 파일을 만듭니다.
+func createLogArchive(dir string) (*logArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	pattern := "buffer_log-" + time.Now().UTC().Format("20060102T150405Z") + "-*.ndjson.gz"
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	gz := gzip.NewWriter(file)
+	return &logArchive{Path: filepath.Clean(file.Name()), file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// Write: 로그를 한 줄씩 쓰고, 지우기 전에 파일까지 내려 쓰도록 Flush/Sync 합니다.
+func (a *logArchive) Write(logs []model.BufferLog) error {
+	for _, log := range logs {
+		err := a.enc.Encode(archivedLog{
+			LogID:           log.LogID,
+			TransactionType: log.TransactionType,
+			TargetTable:     log.TargetTable,
+			Payload:         log.Payload,
+			PayloadVersion:  log.PayloadVersion,
+			TargetRecordID:  log.TargetRecordID,
+			LogUpdatedAt:    log.LogUpdatedAt,
+			AttemptCount:    log.AttemptCount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to archive log %d: %w", log.LogID, err)
+		}
+	}
+	if err := a.gz.Flush(); err != nil {
+		return fmt.Errorf("failed to flush archive: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return nil
+}
+
+func (a *logArchive) Close() error {
+	if err := a.gz.Close(); err != nil {
+		a.file.Close()
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("failed to close archive file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package worker_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+	"restaurant_db/internal/worker"
+)
+
+// TestCompactArchivesOldCommittedLogs: 보관 기간이 지난 반영 완료 로그만 청크 단위로 보관 파일에 쓰고 지우며,
+// 아직 반영되지 않았거나 최근에 반영된 로그와 살아 있는 멱등성 키는 남는지 확인합니다.
+func TestCompactArchivesOldCommittedLogs(t *testing.T) {
+	conn := setupTestDB(t)
+	defer conn.Close()
+	ctx := context.Background()
+	bufferRepo := repository.NewBufferRepository(conn)
+
+	// 1. Given: 로그 6개 중 1 ~ 5는 반영 완료, 1 ~ 3과 미반영 6은 열흘 전에 추가됨
+	var ids []int64
+	for i := 0; i < 6; i++ {
+		log := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Review", Payload: `{}`}
+		if i == 0 {
+			log.IdempotencyKey = "kept-key"
+		}
+		if err := bufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+		ids = append(ids, log.LogID)
+	}
+	if err := bufferRepo.UpdateCommitted(ctx, ids[:5]); err != nil {
+		t.Fatalf("UpdateCommitted failed: %v", err)
+	}
+	old := time.Now().Add(-10 * 24 * time.Hour).UTC().Format("2006-01-02 15:04:05")
+	if _, err := conn.Exec(`UPDATE Buffer_Log SET log_updated_at = ? WHERE log_id IN (?, ?, ?, ?)`,
+		old, ids[0], ids[1], ids[2], ids[5]); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO Buffer_Idempotency_Key (idempotency_key, log_id, expires_at)
+		VALUES ('expired-key', 0, '2000-01-01 00:00:00')`); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// 2. When: 보관 기간 7일, 한 번에 2개씩 압축
+	compactor := worker.NewCompactor(bufferRepo, 7*24*time.Hour, t.TempDir(), time.Hour, nil)
+	compactor.ChunkSize = 2
+	result, err := compactor.Compact(ctx)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	// 3. Then: 오래된 반영 완료 로그 3개만 2번에 나눠 지워지고 만료된 키만 정리됨
+	if result.Deleted != 3 || result.Chunks != 2 || result.KeysPurged != 1 {
+		t.Errorf("Expected 3 logs in 2 chunks and 1 key purged, got %+v", result)
+	}
+	var remaining []int64
+	rows, err := conn.Query("SELECT log_id FROM Buffer_Log ORDER BY log_id")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		rows.Scan(&id)
+		remaining = append(remaining, id)
+	}
+	rows.Close()
+	if len(remaining) != 3 || remaining[0] != ids[3] || remaining[1] != ids[4] || remaining[2] != ids[5] {
+		t.Errorf("Expected logs %v to remain, got %v", ids[3:], remaining)
+	}
+	var keys int
+	conn.QueryRow("SELECT COUNT(*) FROM Buffer_Idempotency_Key").Scan(&keys)
+	if keys != 1 {
+		t.Errorf("Expected live idempotency key to be kept, got %d keys", keys)
+	}
+
+	// 보관 파일에는 지운 로그가 한 줄씩 순서대로 남음
+	file, err := os.Open(result.ArchivePath)
+	if err != nil {
+		t.Fatalf("Expected archive file, got %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Archive is not gzip: %v", err)
+	}
+	var archived []int64
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var line struct {
+			LogID int64 `json:"log_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid archive line %q: %v", scanner.Text(), err)
+		}
+		archived = append(archived, line.LogID)
+	}
+	if len(archived) != 3 || archived[0] != ids[0] || archived[2] != ids[2] {
+		t.Errorf("Expected logs %v in archive, got %v", ids[:3], archived)
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"restaurant_db/internal/model"
+)
+
+// recordKey: 순서를 지켜야 하는 단위 (같은 테이블의 같은 레코드에 대한 로그는 log_id 순으로만 반영)
+type recordKey struct {
+	table    string
+	recordID int64
+}
+
+// keyOf: 로그의 레코드 키를 반환합니다. target_record_id가 없는 로그(Review INSERT 등)는 서로 독립이므로 키가 없습니다.
+func keyOf(log model.BufferLog) (recordKey, bool) {
+	if log.TargetRecordID == 0 {
+		return recordKey{}, false
+	}
+	return recordKey{table: log.TargetTable, recordID: log.TargetRecordID}, true
+}
+
+// partitionLogs: 같은 레코드 키의 로그가 항상 같은 파티션에 log_id 순서 그대로 들어가도록 n개로 나눕니다.
+// 키가 없는 로그는 파티션 크기를 고르게 하기 위해 돌아가며 배정합니다. 빈 파티션은 반환하지 않습니다.
+func partitionLogs(logs []model.BufferLog, n int) [][]model.BufferLog {
+	if n <= 1 {
+		return [][]model.BufferLog{logs}
+	}
+
+	partitions := make([][]model.BufferLog, n)
+	var next int
+	for _, log := range logs {
+		lane := next % n
+		if key, ok := keyOf(log); ok {
+			h := fnv.New32a()
+			h.Write([]byte(key.table))
+			h.Write([]byte(strconv.FormatInt(key.recordID, 10)))
+			lane = int(h.Sum32() % uint32(n))
+		} else {
+			next++
+		}
+		partitions[lane] = append(partitions[lane], log)
+	}
+
+	nonEmpty := partitions[:0]
+	for _, partition := range partitions {
+		if len(partition) > 0 {
+			nonEmpty = append(nonEmpty, partition)
+		}
+	}
+	return nonEmpty
+}
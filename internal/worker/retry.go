@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"math"
+	"time"
+)
+
+// RetryPolicy는 반영에 실패한 버퍼 로그를 언제 다시 시도하고 언제 포기할지 결정합니다.
+type RetryPolicy struct {
+	// MaxAttempts: 이 횟수만큼 실패하면 Dead Letter로 옮김 (0 이하이면 포기하지 않음)
+	MaxAttempts int64
+	// BaseDelay: 첫 실패 후 대기 시간 (실패할 때마다 두 배씩 증가)
+	BaseDelay time.Duration
+	// MaxDelay: 대기 시간의 상한 (0이면 상한 없음)
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy: 1초부터 두 배씩 최대 5분까지 기다리며 5번 실패하면 포기
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    5 * time.Minute,
+	}
+}
+
+// Exhausted: attempts번 실패한 로그를 Dead Letter로 옮겨야 하는지 판단합니다.
+func (p RetryPolicy) Exhausted(attempts int64) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// Backoff: attempts번째 실패 후 다음 시도까지 기다릴 시간 (BaseDelay * 2^(attempts-1), MaxDelay로 제한)
+// MaxDelay가 없어도 time.Duration의 최댓값을 넘지 않습니다.
+func (p RetryPolicy) Backoff(attempts int64) time.Duration {
+	delay := p.BaseDelay
+	for i := int64(1); i < attempts && delay > 0; i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64 // 한 번 더 두 배로 하면 넘치므로 가장 긴 대기 시간으로 고정
+			break
+		}
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
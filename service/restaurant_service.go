@@ -2,29 +2,110 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/logging"
+	"restaurant_db/internal/metrics"
 	"restaurant_db/internal/model"
 	"restaurant_db/internal/repository"
 )
 
+// ErrRestaurantNotFound: 캐시에도 Restaurant 릴레이션에도 없는 식당 ID를 조회했을 때 반환됩니다.
+var ErrRestaurantNotFound = errors.New("restaurant not found")
+
+// DefaultRebuildTimeout: 합쳐진 캐시 재구성 한 번에 쓸 수 있는 기본 최대 시간
+const DefaultRebuildTimeout = 10 * time.Second
+
 type RestaurantService struct {
 	CacheRepo      repository.CacheRepository
 	RestaurantRepo repository.RestaurantRepository
+	// Aggregator: 캐시 미스 시 릴레이션에서 요약을 재구성하고 캐시에 다시 써 넣음
+	Aggregator *cache.RatingAggregator
+	// Policy: cache_score 기반 입장/퇴출 정책 (nil이면 미스마다 항상 캐시에 써 넣음)
+	Policy *cache.Policy
+	// BufferRepo: 요약의 Freshness에 반영 대기 중인 로그 수를 채울 때 사용 (nil이면 세지 않음)
+	BufferRepo repository.BufferRepository
+	// Logger: 조회마다 cache(hit/stale/miss), restaurant_id, duration을 기록
+	Logger *slog.Logger
+	// Metrics: 조회 지연 시간과 캐시 결과별 수 (nil이면 기록하지 않음)
+	Metrics *metrics.Metrics
+
+	// RebuildTimeout: 합쳐진 재구성의 최대 시간. 재구성은 요청한 호출이 취소되어도 이 시간까지 진행됨 (0 이하이면 제한 없음)
+	RebuildTimeout time.Duration
+
+	// misses: 같은 식당에 대한 동시 캐시 미스를 한 번의 재구성으로 합침
+	misses flightGroup
 }
 
-func NewRestaurantService(cacheRepo repository.CacheRepository, restaurantRepo repository.RestaurantRepository) *RestaurantService {
+func NewRestaurantService(
+	cacheRepo repository.CacheRepository,
+	restaurantRepo repository.RestaurantRepository,
+	aggregator *cache.RatingAggregator,
+	policy *cache.Policy,
+	logger *slog.Logger,
+) *RestaurantService {
 	return &RestaurantService{
 		CacheRepo:      cacheRepo,
 		RestaurantRepo: restaurantRepo,
+		Aggregator:     aggregator,
+		Policy:         policy,
+		RebuildTimeout: DefaultRebuildTimeout,
+		Logger:         logging.Component(logger, "restaurant_service"),
 	}
 }
 
-// FindRestaurantSummary: 캐시 우선 조회 로직 (성능 분석용)
-func (s *RestaurantService) FindRestaurantSummary(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
-	// 캐시 조회 시작 시간 기록
+// RestaurantSummary: 식당 평점 요약과 그 값이 얼마나 최신인지에 대한 정보
+type RestaurantSummary struct {
+	model.CacheMetadata
+	Freshness Freshness
+}
+
+// Freshness: 요약의 신선도. 요약은 최종적 일관성(eventual consistency)을 가지므로 호출자가 지연 정도를 판단할 수 있게 합니다.
+type Freshness struct {
+	Age         time.Duration // last_cache_updated_at 이후 지난 시간
+	PendingLogs int64         // 이 식당에 영향을 주지만 아직 반영되지 않은 버퍼 로그 수 (BufferRepo가 없으면 0)
+	Recomputed  bool          // 이번 조회에서 릴레이션으로 다시 계산했는지 (캐시 미스 또는 최대 허용 지연 초과)
+}
+
+// Updating: 반영 대기 중인 변경이 있어 곧 값이 바뀔 수 있는지 (UI의 "업데이트 중" 표시용)
+func (f Freshness) Updating() bool {
+	return f.PendingLogs > 0
+}
+
+// SummaryOption: FindRestaurantSummary의 선택 설정
+type SummaryOption func(*summaryOptions)
+
+type summaryOptions struct {
+	maxStaleness time.Duration
+}
+
+// WithMaxStaleness: 캐시된 요약이 d보다 오래되었으면 릴레이션에서 동기적으로 다시 계산해 캐시를 갱신합니다. (0 이하이면 제한 없음)
+func WithMaxStaleness(d time.Duration) SummaryOption {
+	return func(o *summaryOptions) { o.maxStaleness = d }
+}
+
+// FindRestaurantSummary: 캐시 우선 조회 로직 (Cache-Aside)
+// 캐시에 없으면 Primary 릴레이션에서 요약을 계산해 Cache_Metadata에 채워 넣은 뒤 반환합니다.
+// 반환값의 Freshness로 요약의 나이와 반영 대기 중인 변경 수를 알 수 있습니다.
+func (s *RestaurantService) FindRestaurantSummary(ctx context.Context, restaurantID int64, opts ...SummaryOption) (result *RestaurantSummary, err error) {
+	var options summaryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// 캐시 조회 시작 시간 기록 (없는 식당은 오류가 아니라 정상 조회로 셈)
 	startTime := time.Now()
+	defer func() {
+		observed := err
+		if errors.Is(err, ErrRestaurantNotFound) {
+			observed = nil
+		}
+		s.Metrics.Observe("restaurant_service", "find_restaurant_summary", startTime, observed)
+	}()
 
 	// 1. 캐시 조회 시도
 	cache, err := s.CacheRepo.FindCacheByID(ctx, restaurantID)
@@ -35,23 +116,104 @@ func (s *RestaurantService) FindRestaurantSummary(ctx context.Context, restauran
 
 	if cache != nil {
 		// 캐시 히트
-		duration := time.Since(startTime)
-		fmt.Printf("[Read] CACHE HIT: Restaurant %d 조회 시간: %s\n", restaurantID, duration)
-		return cache, nil
+		s.recordAccess(restaurantID, true)
+
+		age := time.Since(cache.LastCacheUpdatedAt)
+		if options.maxStaleness <= 0 || age <= options.maxStaleness {
+			s.Metrics.CacheRequest(metrics.CacheHit)
+			// 히트는 조회마다 나오므로 Debug (스테일/미스는 재구성이 일어나므로 Info)
+			s.Logger.Debug("restaurant summary read", slog.String("cache", metrics.CacheHit),
+				logging.RestaurantID(restaurantID), logging.Duration(time.Since(startTime)))
+			return s.withFreshness(ctx, cache, false)
+		}
+
+		// 허용 지연을 넘긴 캐시: 이미 캐시에 올라 있으므로 입장 정책 없이 다시 계산해 갱신
+		summary, err, shared := s.misses.Do(ctx, restaurantID, s.RebuildTimeout, func(ctx context.Context) (*model.CacheMetadata, error) {
+			return s.Aggregator.Refresh(ctx, restaurantID)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to access primary relation: %w", err)
+		}
+		if summary == nil {
+			return nil, fmt.Errorf("%w: %d", ErrRestaurantNotFound, restaurantID)
+		}
+		s.Metrics.CacheRequest(metrics.CacheStale)
+		s.Logger.Info("restaurant summary read", slog.String("cache", metrics.CacheStale),
+			logging.RestaurantID(restaurantID), logging.Duration(time.Since(startTime)),
+			slog.Duration("age", age), slog.Duration("max_staleness", options.maxStaleness), slog.Bool("coalesced", shared))
+		return s.withFreshness(ctx, summary, true)
 	}
 
-	// 2. 캐시 미스: 릴레이션 직접 접근 시도
-	fmt.Printf("[Read] CACHE MISS: 릴레이션 직접 접근 (느린 I/O 시뮬레이션 시작)\n")
+	// 2. 캐시 미스: 릴레이션에서 요약을 재구성 (같은 ID의 동시 미스는 하나로 합침)
+	s.recordAccess(restaurantID, false)
 
-	// RestaurantRepo는 느린 I/O를 시뮬레이션
-	_, err = s.RestaurantRepo.FindByID(ctx, restaurantID)
+	summary, err, shared := s.misses.Do(ctx, restaurantID, s.RebuildTimeout, func(ctx context.Context) (*model.CacheMetadata, error) {
+		return s.rebuild(ctx, restaurantID)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to access primary relation: %w", err)
 	}
+	s.Metrics.CacheRequest(metrics.CacheMiss)
+	s.Logger.Info("restaurant summary read", slog.String("cache", metrics.CacheMiss),
+		logging.RestaurantID(restaurantID), logging.Duration(time.Since(startTime)),
+		slog.Bool("coalesced", shared), slog.Bool("found", summary != nil))
+	if summary == nil {
+		return nil, fmt.Errorf("%w: %d", ErrRestaurantNotFound, restaurantID)
+	}
+
+	return s.withFreshness(ctx, summary, true)
+}
+
+// withFreshness: 요약에 나이와 반영 대기 중인 로그 수를 붙입니다.
+func (s *RestaurantService) withFreshness(ctx context.Context, summary *model.CacheMetadata, recomputed bool) (*RestaurantSummary, error) {
+	result := &RestaurantSummary{
+		CacheMetadata: *summary,
+		Freshness: Freshness{
+			Age:        time.Since(summary.LastCacheUpdatedAt),
+			Recomputed: recomputed,
+		},
+	}
+	if s.BufferRepo == nil {
+		return result, nil
+	}
 
-	duration := time.Since(startTime)
-	fmt.Printf("[Read] RELATIONAL ACCESS: Restaurant %d 조회 시간: %s\n", restaurantID, duration)
+	pending, err := s.BufferRepo.CountPendingForRestaurant(ctx, summary.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	result.Freshness.PendingLogs = pending
+	return result, nil
+}
 
-	// 실제로는 캐시를 재구성하고 반환해야 하지만, 분석을 위해 미스 처리
-	return nil, fmt.Errorf("cache miss occurred")
+// rebuild: 릴레이션에서 요약을 계산하고, 캐시 정책이 허용하면 Cache_Metadata에 써 넣습니다.
+func (s *RestaurantService) rebuild(ctx context.Context, restaurantID int64) (*model.CacheMetadata, error) {
+	if s.Policy == nil {
+		return s.Aggregator.Refresh(ctx, restaurantID)
+	}
+
+	admit, err := s.Policy.Admit(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if !admit {
+		// 캐시가 가득 찼고 아직 hot하지 않은 식당: 계산 결과만 돌려주고 캐시에는 올리지 않음
+		return s.Aggregator.Compute(ctx, restaurantID)
+	}
+
+	summary, err := s.Aggregator.Refresh(ctx, restaurantID)
+	if err != nil || summary == nil {
+		return summary, err
+	}
+	if err := s.Policy.Admitted(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// recordAccess: 캐시 정책에 조회를 알립니다. (점수는 정책의 다음 Maintain에서 반영)
+func (s *RestaurantService) recordAccess(restaurantID int64, hit bool) {
+	if s.Policy == nil {
+		return
+	}
+	s.Policy.RecordAccess(restaurantID, hit)
 }
@@ -0,0 +1,210 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"restaurant_db/internal/cache"
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+	"restaurant_db/service"
+)
+
+// countingRestaurantRepo: 릴레이션 접근 횟수를 세고 느린 I/O를 흉내 내는 테스트용 래퍼
+type countingRestaurantRepo struct {
+	repository.RestaurantRepository
+	calls atomic.Int64
+	delay time.Duration
+}
+
+func (r *countingRestaurantRepo) FindByID(ctx context.Context, restaurantID int64) (*model.Restaurant, error) {
+	r.calls.Add(1)
+	time.Sleep(r.delay)
+	return r.RestaurantRepository.FindByID(ctx, restaurantID)
+}
+
+func newRestaurantService(t *testing.T) (*service.RestaurantService, *countingRestaurantRepo, repository.CacheRepository, int64) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ctx := context.Background()
+
+	restaurantRepo := &countingRestaurantRepo{RestaurantRepository: repository.NewRestaurantRepository(conn)}
+	reviewRepo := repository.NewReviewRepository(conn)
+	cacheRepo := repository.NewCacheRepository(conn)
+
+	restaurant := model.Restaurant{Owner: 1, RestaurantName: "식당", RestaurantAddress: "주소", LocationRefID: 1, CategoryRefID: 1}
+	if err := restaurantRepo.Create(ctx, &restaurant); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	review := model.Review{RestaurantRefID: restaurant.RestaurantID, UserRefID: 1, Rating: 4, ReliabilityWeight: 0.5, ReviewContent: "리뷰"}
+	if err := reviewRepo.Create(ctx, &review); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	aggregator := cache.NewRatingAggregator(restaurantRepo, reviewRepo, cacheRepo)
+	return service.NewRestaurantService(cacheRepo, restaurantRepo, aggregator, nil, nil), restaurantRepo, cacheRepo, restaurant.RestaurantID
+}
+
+// TestFindRestaurantSummaryFillsCacheOnMiss: 캐시 미스 시 요약을 계산해 반환하고 캐시에 다시 써 넣는지 확인합니다.
+func TestFindRestaurantSummaryFillsCacheOnMiss(t *testing.T) {
+	svc, _, cacheRepo, restaurantID := newRestaurantService(t)
+	ctx := context.Background()
+
+	summary, err := svc.FindRestaurantSummary(ctx, restaurantID)
+	if err != nil {
+		t.Fatalf("FindRestaurantSummary failed: %v", err)
+	}
+	if summary.WeightedRating != 4 || summary.TotalWeightedReviews != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	cached, err := cacheRepo.FindCacheByID(ctx, restaurantID)
+	if err != nil {
+		t.Fatalf("FindCacheByID failed: %v", err)
+	}
+	if cached == nil {
+		t.Fatalf("Expected cache row to be written back on miss")
+	}
+
+	// 존재하지 않는 식당은 ErrRestaurantNotFound
+	if _, err := svc.FindRestaurantSummary(ctx, 999); !errors.Is(err, service.ErrRestaurantNotFound) {
+		t.Errorf("Expected ErrRestaurantNotFound, got %v", err)
+	}
+}
+
+// TestFindRestaurantSummaryCoalescesMisses: 같은 식당의 동시 캐시 미스가 한 번의 재구성으로 합쳐지는지 확인합니다.
+func TestFindRestaurantSummaryCoalescesMisses(t *testing.T) {
+	svc, restaurantRepo, _, restaurantID := newRestaurantService(t)
+	restaurantRepo.delay = 50 * time.Millisecond
+	ctx := context.Background()
+
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.FindRestaurantSummary(ctx, restaurantID); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("FindRestaurantSummary failed: %v", err)
+	}
+	if calls := restaurantRepo.calls.Load(); calls != 1 {
+		t.Errorf("Expected exactly 1 rebuild, got %d", calls)
+	}
+}
+
+// TestFindRestaurantSummaryDetachesSharedRebuild: 재구성을 시작한 호출이 취소되면 그 호출만 바로 반환하고,
+// 같은 재구성을 기다리던 다른 호출은 결과를 받는지 확인합니다.
+func TestFindRestaurantSummaryDetachesSharedRebuild(t *testing.T) {
+	svc, restaurantRepo, cacheRepo, restaurantID := newRestaurantService(t)
+	restaurantRepo.delay = 100 * time.Millisecond
+
+	// 1. Given: 첫 호출이 재구성을 시작하고, 두 번째 호출이 그 재구성을 기다리는 중
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := svc.FindRestaurantSummary(leaderCtx, restaurantID)
+		leaderErr <- err
+	}()
+	for restaurantRepo.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	type result struct {
+		summary *service.RestaurantSummary
+		err     error
+	}
+	waiter := make(chan result, 1)
+	go func() {
+		summary, err := svc.FindRestaurantSummary(context.Background(), restaurantID)
+		waiter <- result{summary, err}
+	}()
+
+	// 2. When: 첫 호출을 취소
+	start := time.Now()
+	cancelLeader()
+
+	// 3. Then: 첫 호출은 재구성을 기다리지 않고 취소 오류로 끝나고, 두 번째 호출은 결과를 받으며 재구성은 한 번뿐
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to get context.Canceled, got %v", err)
+	}
+	if waited := time.Since(start); waited > 50*time.Millisecond {
+		t.Errorf("Expected the cancelled caller to stop waiting right away, waited %s", waited)
+	}
+	got := <-waiter
+	if got.err != nil || got.summary == nil || got.summary.WeightedRating != 4 {
+		t.Fatalf("Expected the waiting caller to get the rebuilt summary, got %+v, %v", got.summary, got.err)
+	}
+	if calls := restaurantRepo.calls.Load(); calls != 1 {
+		t.Errorf("Expected exactly 1 rebuild, got %d", calls)
+	}
+	if cached, _ := cacheRepo.FindCacheByID(context.Background(), restaurantID); cached == nil {
+		t.Errorf("Expected the detached rebuild to fill the cache")
+	}
+}
+
+// TestFindRestaurantSummaryReportsFreshness: 요약에 반영 대기 중인 로그 수가 붙고,
+// 최대 허용 지연을 넘긴 캐시는 동기적으로 다시 계산되는지 확인합니다.
+func TestFindRestaurantSummaryReportsFreshness(t *testing.T) {
+	svc, _, cacheRepo, restaurantID := newRestaurantService(t)
+	conn := cacheRepo.(*repository.CacheRepoImpl).DB
+	svc.BufferRepo = repository.NewBufferRepository(conn)
+	ctx := context.Background()
+
+	// 1. Given: 캐시된 요약과, 이 식당에 대한 반영 대기 중인 리뷰 로그 1개와 다른 식당 로그 1개
+	if _, err := svc.FindRestaurantSummary(ctx, restaurantID); err != nil {
+		t.Fatalf("FindRestaurantSummary failed: %v", err)
+	}
+	for _, id := range []int64{restaurantID, restaurantID + 1} {
+		log := model.BufferLog{TransactionType: model.TransactionInsert, TargetTable: "Review",
+			Payload: fmt.Sprintf(`{"restaurant_id": %d, "user_id": 1, "rating": 5, "review_content": "새 리뷰", "reliability_weight": 1}`, id)}
+		if err := svc.BufferRepo.AddLog(ctx, &log); err != nil {
+			t.Fatalf("AddLog failed: %v", err)
+		}
+	}
+
+	// 2. When & 3. Then: 캐시 히트에도 대기 중인 로그 수가 보임
+	summary, err := svc.FindRestaurantSummary(ctx, restaurantID)
+	if err != nil {
+		t.Fatalf("FindRestaurantSummary failed: %v", err)
+	}
+	if summary.Freshness.Recomputed || summary.Freshness.PendingLogs != 1 || !summary.Freshness.Updating() {
+		t.Errorf("Expected cached summary with 1 pending log, got %+v", summary.Freshness)
+	}
+
+	// 캐시를 한 시간 전 값으로 만들면, 허용 지연 1분을 넘기므로 다시 계산됨
+	old := time.Now().Add(-time.Hour).UTC().Format("2006-01-02 15:04:05")
+	if _, err := conn.ExecContext(ctx, "UPDATE Cache_Metadata SET last_cache_updated_at = ?", old); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	stale, err := svc.FindRestaurantSummary(ctx, restaurantID, service.WithMaxStaleness(time.Hour*2))
+	if err != nil || stale.Freshness.Recomputed || stale.Freshness.Age < time.Hour {
+		t.Errorf("Expected summary within max staleness to be served from cache, got %+v, %v", stale, err)
+	}
+	fresh, err := svc.FindRestaurantSummary(ctx, restaurantID, service.WithMaxStaleness(time.Minute))
+	if err != nil {
+		t.Fatalf("FindRestaurantSummary failed: %v", err)
+	}
+	if !fresh.Freshness.Recomputed || fresh.Freshness.Age > time.Minute {
+		t.Errorf("Expected stale summary to be recomputed, got %+v", fresh.Freshness)
+	}
+	if cached, _ := cacheRepo.FindCacheByID(ctx, restaurantID); cached == nil || time.Since(cached.LastCacheUpdatedAt) > time.Minute {
+		t.Errorf("Expected recompute to refresh the cache row, got %+v", cached)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"restaurant_db/internal/buffer"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+)
+
+var (
+	// ErrUserNotFound: 리뷰 작성자가 User 테이블에 없을 때 반환됩니다.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidReview: 평점 범위나 내용이 올바르지 않을 때 반환됩니다.
+	ErrInvalidReview = errors.New("invalid review")
+)
+
+const (
+	MinRating = model.MinRating
+	MaxRating = model.MaxRating
+)
+
+type ReviewService struct {
+	UserRepo   repository.UserRepository
+	BufferRepo repository.BufferRepository
+}
+
+func NewReviewService(userRepo repository.UserRepository, bufferRepo repository.BufferRepository) *ReviewService {
+	return &ReviewService{
+		UserRepo:   userRepo,
+		BufferRepo: bufferRepo,
+	}
+}
+
+// SubmitReview: 멱등성 키 없이 리뷰를 제출합니다. (SubmitReviewWithKey 참고)
+func (s *ReviewService) SubmitReview(ctx context.Context, review *model.Review) (int64, error) {
+	return s.SubmitReviewWithKey(ctx, review, "")
+}
+
+// SubmitReviewWithKey: 리뷰 쓰기 경로. Review 테이블에 직접 쓰지 않고 Buffer_Log에 INSERT 로그를 남깁니다.
+// 작성 시점의 유저 reliability_score를 reliability_weight로 스냅샷하여 payload에 담으며,
+// 실제 Review 레코드는 CheckpointWorker가 배치로 반영합니다. 반환값은 생성된 버퍼 로그 ID입니다.
+// 타임아웃 후 같은 요청을 다시 보내는 클라이언트는 같은 idempotencyKey를 넘기면 리뷰가 두 번 쌓이지 않고 처음 로그 ID를 받습니다.
+func (s *ReviewService) SubmitReviewWithKey(ctx context.Context, review *model.Review, idempotencyKey string) (int64, error) {
+	if review.Rating < MinRating || review.Rating > MaxRating {
+		return 0, fmt.Errorf("%w: rating %.1f out of range [%.0f, %.0f]", ErrInvalidReview, review.Rating, MinRating, MaxRating)
+	}
+	if review.ReviewContent == "" {
+		return 0, fmt.Errorf("%w: empty review content", ErrInvalidReview)
+	}
+
+	// 1. 작성자의 현재 신뢰도 조회
+	user, err := s.UserRepo.FindByID(ctx, review.UserRefID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, fmt.Errorf("%w: %d", ErrUserNotFound, review.UserRefID)
+	}
+
+	// 2. 신뢰도 스냅샷 (이후 유저 점수가 바뀌어도 이 리뷰의 가중치는 유지됨)
+	review.ReliabilityWeight = user.ReliabilityScore
+
+	// 3. 버퍼에 INSERT 로그 기록 (review_id는 Worker가 반영할 때 할당되므로 target_record_id는 0)
+	bufferLog, err := buffer.NewReviewInsertLog(model.ReviewPayload{
+		RestaurantID:      review.RestaurantRefID,
+		UserID:            review.UserRefID,
+		Rating:            review.Rating,
+		ReviewContent:     review.ReviewContent,
+		ReliabilityWeight: review.ReliabilityWeight,
+	})
+	if err != nil {
+		return 0, err
+	}
+	bufferLog.IdempotencyKey = idempotencyKey
+	if err := s.BufferRepo.AddLog(ctx, &bufferLog); err != nil {
+		return 0, fmt.Errorf("failed to buffer review: %w", err)
+	}
+
+	return bufferLog.LogID, nil
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"restaurant_db/internal/db"
+	"restaurant_db/internal/model"
+	"restaurant_db/internal/repository"
+	"restaurant_db/service"
+)
+
+// TestSubmitReviewBuffersInsert: SubmitReview가 Review 대신 Buffer_Log에 신뢰도 스냅샷이 담긴 INSERT 로그를 남기는지 확인합니다.
+func TestSubmitReviewBuffersInsert(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	userRepo := repository.NewUserRepository(conn)
+	bufferRepo := repository.NewBufferRepository(conn)
+	svc := service.NewReviewService(userRepo, bufferRepo)
+
+	// 1. Given: 신뢰도 0.8인 유저
+	user := model.User{Username: "reviewer"}
+	if err := userRepo.Create(ctx, &user); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := userRepo.UpdateReliabilityScore(ctx, user.UserID, 0.8, 3, 0); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// 2. When: 리뷰 제출
+	logID, err := svc.SubmitReview(ctx, &model.Review{
+		RestaurantRefID: 1,
+		UserRefID:       user.UserID,
+		Rating:          4.5,
+		ReviewContent:   "국물이 진해요",
+	})
+	if err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+
+	// 3. Then: Review 테이블은 비어 있고 버퍼에 로그가 쌓임
+	var reviewCount int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM Review").Scan(&reviewCount); err != nil {
+		t.Fatalf("Failed to count reviews: %v", err)
+	}
+	if reviewCount != 0 {
+		t.Errorf("Expected Review table to be untouched, got %d rows", reviewCount)
+	}
+
+	logs, err := bufferRepo.GetPendingLogs(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingLogs failed: %v", err)
+	}
+	if len(logs) != 1 || logs[0].LogID != logID {
+		t.Fatalf("Expected one pending log with ID %d, got %+v", logID, logs)
+	}
+	if logs[0].TransactionType != "INSERT" || logs[0].TargetTable != "Review" {
+		t.Errorf("Unexpected log type: %+v", logs[0])
+	}
+
+	var payload model.ReviewPayload
+	if err := json.Unmarshal([]byte(logs[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.ReliabilityWeight != 0.8 || payload.Rating != 4.5 {
+		t.Errorf("Expected snapshot weight 0.8 and rating 4.5, got %+v", payload)
+	}
+}
+
+// TestSubmitReviewRejectsInvalid: 평점 범위 밖이거나 없는 유저의 리뷰는 버퍼에 기록되지 않아야 합니다.
+func TestSubmitReviewRejectsInvalid(t *testing.T) {
+	conn, err := db.InitDB(db.MemoryDSNFor(t.Name()))
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	svc := service.NewReviewService(repository.NewUserRepository(conn), repository.NewBufferRepository(conn))
+
+	_, err = svc.SubmitReview(ctx, &model.Review{RestaurantRefID: 1, UserRefID: 1, Rating: 6, ReviewContent: "?"})
+	if !errors.Is(err, service.ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview, got %v", err)
+	}
+
+	_, err = svc.SubmitReview(ctx, &model.Review{RestaurantRefID: 1, UserRefID: 999, Rating: 3, ReviewContent: "보통"})
+	if !errors.Is(err, service.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"restaurant_db/internal/model"
+)
+
+// flightCall: 진행 중인 캐시 재구성 한 건
+type flightCall struct {
+	done chan struct{} // fn이 끝나면 닫힘
+	val  *model.CacheMetadata
+	err  error
+}
+
+// flightGroup은 같은 식당 ID에 대한 동시 캐시 미스를 하나의 재구성으로 합칩니다.
+// 먼저 도착한 호출이 fn을 시작하고, 나머지는 그 결과를 기다렸다가 함께 받습니다.
+// fn은 호출자의 취소와 분리된 ctx에서 실행되므로, 먼저 온 호출이 취소되어도 기다리던 호출들은 결과를 받습니다.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[int64]*flightCall
+}
+
+// Do: key에 대해 fn을 최대 한 번만 동시에 실행합니다. shared는 다른 호출이 시작한 재구성을 기다렸는지 여부입니다.
+// fn은 ctx의 값만 이어받고 timeout(0 이하이면 제한 없음)이 지나면 취소되는 ctx로 실행되며,
+// 각 호출은 자신의 ctx가 끝나면 fn을 기다리지 않고 ctx.Err()를 반환합니다. (fn은 계속 진행되어 다른 호출에 결과를 줌)
+func (g *flightGroup) Do(ctx context.Context, key int64, timeout time.Duration, fn func(ctx context.Context) (*model.CacheMetadata, error)) (val *model.CacheMetadata, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[int64]*flightCall)
+	}
+	c, shared := g.calls[key]
+	if !shared {
+		c = &flightCall{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(ctx, key, timeout, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return copySummary(c.val), c.err, shared
+	case <-ctx.Done():
+		return nil, ctx.Err(), shared
+	}
+}
+
+// run: fn을 분리된 ctx로 실행하고, 결과를 기록한 뒤 기다리는 호출들을 깨웁니다.
+func (g *flightGroup) run(ctx context.Context, key int64, timeout time.Duration, c *flightCall, fn func(ctx context.Context) (*model.CacheMetadata, error)) {
+	ctx = context.WithoutCancel(ctx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c.val, c.err = fn(ctx)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// copySummary: 호출자마다 독립된 결과를 돌려주기 위한 복사
+func copySummary(summary *model.CacheMetadata) *model.CacheMetadata {
+	if summary == nil {
+		return nil
+	}
+	copied := *summary
+	return &copied
+}